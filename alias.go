@@ -0,0 +1,120 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// describeAliases derives the environment variables and long flags of the
+// former names of the field, as specified by its `aliases` tag, in the same
+// way as those of the field, so that the former names continue to work,
+// e.g. `aliases:"ListenAddr"` for the field `Server.Listen` derives
+// `SERVER_LISTEN_ADDR` and `--server-listen-addr`
+func (f *field) describeAliases(p *parent, prefix string, options ProcessingOptions) {
+	for _, alias := range strings.Split(f.tag.Get("aliases"), ",") {
+		if alias = strings.TrimSpace(alias); alias == "" {
+			continue
+		}
+		if f.env != "" {
+			env := strings.ToUpper(splitIntoWords(alias, options.EnvSeparator))
+			env = prefixedEnv(join(p.env, options.EnvSeparator, env), prefix, options.EnvSeparator, false)
+			if options.Flags&WithSanitizedEnv != 0 {
+				env = sanitizeEnv(env)
+			}
+			f.aliasEnvs = append(f.aliasEnvs, env)
+		}
+		if f.long != "" {
+			long := strings.ToLower(splitIntoWords(alias, options.LongSeparator))
+			f.aliasLongs = append(f.aliasLongs, join(p.long, options.LongSeparator, long))
+		}
+	}
+}
+
+// aliasEnv returns the value of the first of the field's alias environment
+// variables that is set, if the field's own environment variable is not
+func (f *field) aliasEnv() (string, bool) {
+	if value, ok := os.LookupEnv(f.env); ok && value != "" {
+		return "", false
+	}
+	for _, env := range f.aliasEnvs {
+		if value, ok := os.LookupEnv(env); ok && value != "" {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// registerAliases registers a hidden flag, of the same type as the field's
+// flag, for each of the field's alias long flags
+func registerAliases(flagSet *pflag.FlagSet, f *field, defaultValue interface{}) error {
+	for _, long := range f.aliasLongs {
+		if flagSet.Lookup(long) != nil {
+			return configErrorf(f, "aliases", "duplicate flag '--%s' for alias, already defined in the flag set", long)
+		}
+		alias := *f
+		alias.long = long
+		alias.short = ""
+		alias.help = fmt.Sprintf("alias for --%s", f.long)
+		registerFlag(flagSet, &alias, defaultValue)
+		_ = flagSet.MarkHidden(long)
+	}
+	return nil
+}
+
+// changedFlag returns the field's flag if it was set or, otherwise, the
+// first of its alias flags, in the order of the `aliases` tag, that was
+// set. Nil is returned if none were set.
+func changedFlag(flagSet *pflag.FlagSet, f *field) *pflag.Flag {
+	for _, long := range append([]string{f.long}, f.aliasLongs...) {
+		if flag := flagSet.Lookup(long); flag != nil && flag.Changed {
+			return flag
+		}
+	}
+	return nil
+}
+
+// aliasedFlag implements the viper.FlagValue interface for a flag with
+// aliases, so that viper resolves the value of the flag or, if it was not
+// set, of the first alias that was set
+type aliasedFlag struct {
+	flagSet *pflag.FlagSet
+	field   *field
+	flag    *pflag.Flag
+}
+
+func (a *aliasedFlag) HasChanged() bool {
+	return changedFlag(a.flagSet, a.field) != nil
+}
+
+func (a *aliasedFlag) Name() string {
+	return a.flag.Name
+}
+
+func (a *aliasedFlag) ValueString() string {
+	if flag := changedFlag(a.flagSet, a.field); flag != nil {
+		return flag.Value.String()
+	}
+	return a.flag.Value.String()
+}
+
+func (a *aliasedFlag) ValueType() string {
+	return a.flag.Value.Type()
+}
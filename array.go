@@ -0,0 +1,66 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"strconv"
+	"strings"
+)
+
+// isArray returns true if the field is bound to a boolean array flag, i.e.
+// one to which a value is appended each time it is specified, e.g.
+// `--feature --feature=false`, rather than one whose value is split
+func (f *field) isArray() bool {
+	return isTrue(f.tag.Get("array"))
+}
+
+// boolArrayValue implements the pflag.Value interface for a []bool to which
+// the value of each occurrence of the flag is appended, so that the flag
+// can be specified without a value, as for a bool flag. As with pflag's
+// slice values, the first value set replaces the default.
+type boolArrayValue struct {
+	value   []bool
+	changed bool
+}
+
+func newBoolArrayValue(val []bool) *boolArrayValue {
+	return &boolArrayValue{value: val}
+}
+
+func (b *boolArrayValue) String() string {
+	parts := make([]string, len(b.value))
+	for i, v := range b.value {
+		parts[i] = strconv.FormatBool(v)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func (b *boolArrayValue) Set(value string) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	if !b.changed {
+		b.value = nil
+		b.changed = true
+	}
+	b.value = append(b.value, parsed)
+	return nil
+}
+
+func (b *boolArrayValue) Type() string {
+	return "boolArray"
+}
@@ -0,0 +1,76 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestBoolArray(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		args []string
+		want []bool
+	}{
+		{"default", nil, []bool{false, true}},
+		{"repeated", []string{"--feature", "--feature=false", "--feature"}, []bool{true, false, true}},
+		{"single", []string{"--feature=false"}, []bool{false}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var c struct {
+				Feature []bool `array:"true" default:"false,true"`
+			}
+			p, err := New(&c, WithDefault, WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(c.Feature) != fmt.Sprint(test.want) {
+				t.Errorf("expected Feature to be %v, got %v", test.want, c.Feature)
+			}
+		})
+	}
+}
+
+func TestBoolArrayInvalid(t *testing.T) {
+	var c struct {
+		Feature []bool `array:"true"`
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.FlagSet().SetOutput(&strings.Builder{})
+	if err := p.Parse([]string{"--feature=maybe"}); err == nil {
+		t.Error("expected an error for a value that is not a bool")
+	}
+
+	var s struct {
+		Features []string `array:"true"`
+	}
+	_, err = New(&s, WithDefault, WithViper(viper.New()))
+	if err == nil || !strings.Contains(err.Error(), "array is only valid for []bool fields") {
+		t.Errorf("expected an error for array on a string list, got '%v'", err)
+	}
+}
@@ -0,0 +1,85 @@
+//go:build go1.18
+// +build go1.18
+
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+)
+
+// Bindable the types of the values that can be bound using Bind, which
+// include time.Duration and named types such as `type Level int`
+type Bindable interface {
+	~string | ~bool |
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Bind binds the target to the processor's flag set and viper instance as
+// if it were a member of the configuration specification with the given
+// name, which must be a valid exported Go identifier, e.g. "MaxConns" is
+// bound to the key `maxconns`, the environment variable `PREFIX_MAX_CONNS`,
+// and the flag `--max-conns`. The current value of the target, if not the
+// zero value, is used as the default. The target is set by Apply.
+//
+// Bind must be called before the flag set is parsed.
+func Bind[T Bindable](p *Processor, name string, target *T) error {
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return fmt.Errorf("bind '%s': name must be an exported identifier", name)
+	}
+
+	// The target is described as the only member of a struct so that it
+	// is named exactly as a member of the specification would be
+	typ := reflect.TypeOf(target).Elem()
+	spec := reflect.New(reflect.StructOf([]reflect.StructField{{Name: name, Type: typ}}))
+	options := p.options
+	options.Flags &^= OnlyTagged
+	fields, err := describeStruct(spec.Elem(), &parent{key: options.KeyNamespace}, p.prefix, options)
+	if err != nil {
+		return err
+	}
+	f := fields[0]
+
+	value := reflect.ValueOf(target).Elem()
+	if !value.IsZero() {
+		f.def = formatValue(f, value)
+	}
+
+	for _, other := range p.fields {
+		if other.key == f.key {
+			return fmt.Errorf("bind '%s': key '%s' is already bound to field '%s'", name, f.key, other.name)
+		}
+	}
+	for _, other := range p.bound {
+		if other.field.key == f.key {
+			return fmt.Errorf("bind '%s': key '%s' is already bound", name, f.key)
+		}
+	}
+	if f.long != "" && p.flagSet.Lookup(f.long) != nil {
+		return fmt.Errorf("bind '%s': flag '--%s' is already defined", name, f.long)
+	}
+
+	if err := bindField(p.viper, p.flagSet, f, options); err != nil {
+		return err
+	}
+	p.bound = append(p.bound, binding{field: f, target: value})
+	return nil
+}
@@ -0,0 +1,101 @@
+//go:build go1.18
+// +build go1.18
+
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestBind(t *testing.T) {
+	var c struct {
+		Host string `default:"localhost"`
+	}
+	os.Setenv("BIND_MAX_CONNS", "20")
+	defer os.Unsetenv("BIND_MAX_CONNS")
+
+	p, err := New(&c, WithDefault, WithPrefix("BIND"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxConns := 10
+	timeout := 5 * time.Second
+	level := testInfo
+	var verbose bool
+	if err := Bind(p, "MaxConns", &maxConns); err != nil {
+		t.Fatal(err)
+	}
+	if err := Bind(p, "Timeout", &timeout); err != nil {
+		t.Fatal(err)
+	}
+	if err := Bind(p, "Level", &level); err != nil {
+		t.Fatal(err)
+	}
+	if err := Bind(p, "Verbose", &verbose); err != nil {
+		t.Fatal(err)
+	}
+	if def := p.FlagSet().Lookup("timeout").DefValue; def != "5s" {
+		t.Errorf("expected the current value to be the default, got '%s'", def)
+	}
+
+	if err := p.Parse([]string{"--timeout=1m", "--level=warn", "--verbose"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if maxConns != 20 || timeout != time.Minute || level != testWarn || !verbose || c.Host != "localhost" {
+		t.Errorf("expected the bound values to be set, got %d %s %s %t", maxConns, timeout, level, verbose)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	var c struct {
+		Host string
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s string
+	var n int
+	if err := Bind(p, "Port", &n); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		bind func() error
+		want string
+	}{
+		{name: "unexported", bind: func() error { return Bind(p, "port", &n) }, want: "name must be an exported identifier"},
+		{name: "not an identifier", bind: func() error { return Bind(p, "Max-Conns", &n) }, want: "name must be an exported identifier"},
+		{name: "field key", bind: func() error { return Bind(p, "Host", &s) }, want: "key 'host' is already bound to field 'Host'"},
+		{name: "bound key", bind: func() error { return Bind(p, "Port", &n) }, want: "key 'port' is already bound"},
+	}
+	for _, test := range tests {
+		if err := test.bind(); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: expected an error containing '%s', got '%v'", test.name, test.want, err)
+		}
+	}
+}
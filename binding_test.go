@@ -0,0 +1,50 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestRequireBinding(t *testing.T) {
+	type spec struct {
+		Host    string `env:"HOST"`
+		Port    int    `long:"port"`
+		Name    string `key:"name"`
+		Lost    string
+		Forgot  int
+		Skipped string `ignored:"true"`
+	}
+	options := ProcessingOptions{Flags: WithRequireBinding}
+	viper.Reset()
+	defer viper.Reset()
+	err := AddConfiguration(pflag.NewFlagSet("test", pflag.ContinueOnError), &spec{}, "", options, nil)
+	if err == nil {
+		t.Fatal("expected an error for fields that cannot be configured")
+	}
+	if !strings.HasPrefix(err.Error(), "fields 'Lost', 'Forgot' have no environment variable, flag, or key") {
+		t.Errorf("expected the error to list 'Lost' and 'Forgot', got '%s'", err)
+	}
+
+	options.Flags |= WithDefault
+	if err := AddConfiguration(pflag.NewFlagSet("test", pflag.ContinueOnError), &spec{}, "", options, nil); err != nil {
+		t.Errorf("expected no error when names are generated, got '%s'", err)
+	}
+}
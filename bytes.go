@@ -0,0 +1,76 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// isBytesType returns true if the type is a list of bytes, e.g. []byte,
+// which is bound as a single encoded value rather than as a list
+func isBytesType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+}
+
+// encoding returns the encoding of a []byte field, as specified by the
+// `encoding` tag, either "hex", the default, or "base64"
+func (f *field) encoding() (string, error) {
+	switch tag := f.tag.Get("encoding"); tag {
+	case "", "hex":
+		return "hex", nil
+	case "base64":
+		return tag, nil
+	default:
+		return "", fmt.Errorf("invalid encoding '%s', must be one of 'hex' or 'base64'", tag)
+	}
+}
+
+// checkEncoding returns an error if the field's `encoding` tag is invalid
+// or is specified for a field that is not a []byte
+func (f *field) checkEncoding() error {
+	if f.tag.Get("encoding") == "" {
+		return nil
+	}
+	if !isBytesType(f.typ) || isTextType(f.typ) {
+		return fmt.Errorf("encoding is only valid for []byte fields")
+	}
+	_, err := f.encoding()
+	return err
+}
+
+// parseBytes decodes a value of a []byte field using the field's encoding
+func (f *field) parseBytes(value string) ([]byte, error) {
+	encoding, err := f.encoding()
+	if err != nil {
+		return nil, err
+	}
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(value)
+	}
+	return hex.DecodeString(value)
+}
+
+// formatBytes encodes the value of a []byte field using the field's
+// encoding
+func (f *field) formatBytes(value []byte) string {
+	if encoding, _ := f.encoding(); encoding == "base64" {
+		return base64.StdEncoding.EncodeToString(value)
+	}
+	return hex.EncodeToString(value)
+}
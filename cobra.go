@@ -0,0 +1,59 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// AddConfigurationToCommand is as AddConfigurationTo, but binds the
+// configuration to the flags of a cobra command. The tags are applied using
+// the mechanisms that cobra honors, i.e. `help` is the usage of the flag,
+// `deprecated` and `hidden` mark the flag as such, and `group` is recorded
+// as an annotation of the flag, as read by UsageTemplate. The values of a
+// flag whose member has a `choices` tag, or is of a registered enum type,
+// are offered by cobra's shell completion. When WithProgramPrefix is set
+// and no prefix is given, the prefix is derived from the name of the root
+// command.
+func AddConfigurationToCommand(v *viper.Viper, cmd *cobra.Command, configSpecification interface{}, prefix string, options ProcessingOptions) error {
+	if prefix == "" && options.Flags&WithProgramPrefix != 0 {
+		prefix = programPrefix(cmd.Root().Name())
+	}
+	fields, err := addConfiguration(v, cmd.Flags(), configSpecification, prefix, options)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		values := completionValues(f)
+		if values == nil || f.long == "" || cmd.Flags().Lookup(f.long) == nil {
+			continue
+		}
+		if err := cmd.RegisterFlagCompletionFunc(f.long, completeValues(values)); err != nil {
+			return configError(f, "", err)
+		}
+	}
+	return nil
+}
+
+// completeValues returns a cobra completion function that offers the given
+// values, rather than file names, as the argument of a flag
+func completeValues(values []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,136 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type commandSpec struct {
+	Host    string     `short:"H" help:"the host to connect to" group:"Connection"`
+	Port    int        `help:"the port" deprecated:"use --host with a port instead"`
+	Debug   bool       `hidden:"true"`
+	Level   string     `choices:"debug, info, warn" default:"info"`
+	Month   time.Month `default:"March"`
+	Timeout time.Duration
+}
+
+func newTestCommand(t *testing.T) (*cobra.Command, *viper.Viper) {
+	cmd := &cobra.Command{Use: "myapp", Run: func(*cobra.Command, []string) {}}
+	v := viper.New()
+	if err := AddConfigurationToCommand(v, cmd, &commandSpec{}, "", DefaultOptions); err != nil {
+		t.Fatal(err)
+	}
+	return cmd, v
+}
+
+func TestAddConfigurationToCommandAnnotations(t *testing.T) {
+	cmd, _ := newTestCommand(t)
+	flags := cmd.Flags()
+
+	host := flags.Lookup("host")
+	if host == nil || host.Shorthand != "H" || host.Usage != "the host to connect to" {
+		t.Fatalf("expected --host, -H with its help, got %+v", host)
+	}
+	if got := host.Annotations[groupAnnotation]; !reflect.DeepEqual(got, []string{"Connection"}) {
+		t.Errorf("expected the group annotation, got %v", got)
+	}
+	if got := flags.Lookup("port").Deprecated; got != "use --host with a port instead" {
+		t.Errorf("expected --port to be deprecated, got '%s'", got)
+	}
+	if !flags.Lookup("debug").Hidden {
+		t.Error("expected --debug to be hidden")
+	}
+
+	usage := cmd.UsageString()
+	if strings.Contains(usage, "--debug") || strings.Contains(usage, "--port") {
+		t.Errorf("expected hidden and deprecated flags to be omitted from the usage, got:\n%s", usage)
+	}
+	if !strings.Contains(usage, "the host to connect to") {
+		t.Errorf("expected the help in the usage, got:\n%s", usage)
+	}
+}
+
+func TestAddConfigurationToCommandCompletion(t *testing.T) {
+	tests := []struct {
+		flag string
+		want []string
+	}{
+		{flag: "--level", want: []string{"debug", "info", "warn"}},
+		{flag: "--month", want: []string{"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December"}},
+	}
+	for _, test := range tests {
+		t.Run(test.flag, func(t *testing.T) {
+			cmd, _ := newTestCommand(t)
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetArgs([]string{cobra.ShellCompRequestCmd, test.flag, ""})
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			// The completions are followed by the directive, e.g. `:4`
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if got := lines[:len(lines)-1]; !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected completions %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestAddConfigurationToCommandBinding(t *testing.T) {
+	cmd, v := newTestCommand(t)
+	cmd.SetArgs([]string{"--host", "db", "--timeout", "5s"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var c commandSpec
+	if err := PopulateFrom(v, &c, "", DefaultOptions); err != nil {
+		t.Fatal(err)
+	}
+	if c.Host != "db" || c.Timeout != 5*time.Second || c.Level != "info" || c.Month != time.March {
+		t.Errorf("unexpected configuration %+v", c)
+	}
+}
+
+func TestAddConfigurationToCommandProgramPrefix(t *testing.T) {
+	os.Setenv("MY_APP_HOST", "from-env")
+	defer os.Unsetenv("MY_APP_HOST")
+
+	root := &cobra.Command{Use: "my-app"}
+	sub := &cobra.Command{Use: "serve"}
+	root.AddCommand(sub)
+
+	options := DefaultOptions
+	options.Flags |= WithProgramPrefix
+	v := viper.New()
+	if err := AddConfigurationToCommand(v, sub, &struct{ Host string }{}, "", options); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.GetString("host"); got != "from-env" {
+		t.Errorf("expected the prefix to be derived from the root command, got '%s'", got)
+	}
+}
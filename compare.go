@@ -0,0 +1,166 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+)
+
+// ChangeKind indicates how a flag or environment variable changed between
+// two configuration specifications
+type ChangeKind int
+
+// Defines the kinds of change reported by CompareSpecs
+const (
+	// SpecAdded a flag or environment variable only in the new specification
+	SpecAdded ChangeKind = iota
+
+	// SpecRemoved a flag or environment variable only in the old
+	// specification
+	SpecRemoved
+
+	// SpecRetyped a flag or environment variable in both specifications
+	// whose member has a different type
+	SpecRetyped
+)
+
+// String returns the name of the kind of change
+func (k ChangeKind) String() string {
+	switch k {
+	case SpecAdded:
+		return "added"
+	case SpecRemoved:
+		return "removed"
+	case SpecRetyped:
+		return "retyped"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// SpecChange describes a flag or environment variable that was added,
+// removed, or retyped between two configuration specifications
+type SpecChange struct {
+	Kind ChangeKind
+
+	// Flag the long flag that changed, without the leading `--`, if the
+	// change concerns a flag
+	Flag string
+
+	// Env the environment variable that changed, if the change concerns an
+	// environment variable
+	Env string
+
+	// Field the path of the member bound to the flag or environment
+	// variable, in the new specification unless it was removed
+	Field string
+
+	// OldType the type of the member in the old specification, unless added
+	OldType string
+
+	// NewType the type of the member in the new specification, unless
+	// removed
+	NewType string
+}
+
+func (c SpecChange) String() string {
+	name := "environment variable '" + c.Env + "'"
+	if c.Flag != "" {
+		name = "flag '--" + c.Flag + "'"
+	}
+	switch c.Kind {
+	case SpecAdded:
+		return fmt.Sprintf("added %s (%s)", name, c.NewType)
+	case SpecRemoved:
+		return fmt.Sprintf("removed %s (%s)", name, c.OldType)
+	}
+	return fmt.Sprintf("retyped %s from %s to %s", name, c.OldType, c.NewType)
+}
+
+// CompareSpecs compares the flags and environment variables generated for
+// two configuration specifications, e.g. from consecutive releases, using
+// DefaultOptions, and reports those that were added or removed, and those
+// whose member's type changed, e.g. for release notes or backward
+// compatibility checks. A renamed member is reported as the removal of its
+// old names and the addition of its new names. Only the generated names and
+// types are compared, not any values. The flags are reported before the
+// environment variables, each in the order of the old specification
+// followed by the additions in the order of the new specification.
+func CompareSpecs(oldSpec, newSpec interface{}) ([]SpecChange, error) {
+	oldFields, err := describeFields(oldSpec, "", DefaultOptions)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := describeFields(newSpec, "", DefaultOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the flags that are generated, for supported types, are compared
+	flag := func(f *field) string {
+		if f.supported() {
+			return f.long
+		}
+		return ""
+	}
+	env := func(f *field) string {
+		return f.env
+	}
+
+	changes := compareNames(oldFields, newFields, flag, func(c *SpecChange, name string) { c.Flag = name })
+	changes = append(changes, compareNames(oldFields, newFields, env, func(c *SpecChange, name string) { c.Env = name })...)
+	return changes, nil
+}
+
+// compareNames compares the names, as returned by the name function, of the
+// old and new fields, returning a change, whose name is set by the set
+// function, for each name that was added, removed, or whose field's type
+// changed. Fields without a name are not compared.
+func compareNames(oldFields, newFields []*field, name func(*field) string, set func(*SpecChange, string)) []SpecChange {
+	byName := map[string]*field{}
+	for _, f := range newFields {
+		if n := name(f); n != "" {
+			byName[n] = f
+		}
+	}
+
+	var changes []SpecChange
+	seen := map[string]bool{}
+	for _, old := range oldFields {
+		n := name(old)
+		if n == "" {
+			continue
+		}
+		seen[n] = true
+		var c SpecChange
+		if f, ok := byName[n]; !ok {
+			c = SpecChange{Kind: SpecRemoved, Field: old.name, OldType: old.typ.String()}
+		} else if f.typ.String() != old.typ.String() {
+			c = SpecChange{Kind: SpecRetyped, Field: f.name, OldType: old.typ.String(), NewType: f.typ.String()}
+		} else {
+			continue
+		}
+		set(&c, n)
+		changes = append(changes, c)
+	}
+	for _, f := range newFields {
+		if n := name(f); n != "" && !seen[n] {
+			c := SpecChange{Kind: SpecAdded, Field: f.name, NewType: f.typ.String()}
+			set(&c, n)
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
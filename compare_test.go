@@ -0,0 +1,91 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompareSpecs(t *testing.T) {
+	type oldSpec struct {
+		Host    string
+		Port    int
+		Timeout int
+		Legacy  bool
+	}
+	type newSpec struct {
+		Host    string
+		Port    int
+		Timeout time.Duration
+		Address string `env:"LISTEN_ADDRESS"`
+	}
+	changes, err := CompareSpecs(&oldSpec{}, &newSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"retyped flag '--timeout' from int to time.Duration",
+		"removed flag '--legacy' (bool)",
+		"added flag '--address' (string)",
+		"retyped environment variable 'TIMEOUT' from int to time.Duration",
+		"removed environment variable 'LEGACY' (bool)",
+		"added environment variable 'LISTEN_ADDRESS' (string)",
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected the changes %q, got %v", want, changes)
+	}
+	for i := range want {
+		if got := changes[i].String(); got != want[i] {
+			t.Errorf("expected change %d to be '%s', got '%s'", i, want[i], got)
+		}
+	}
+	if c := changes[0]; c.Kind != SpecRetyped || c.Field != "Timeout" || c.Flag != "timeout" || c.Env != "" {
+		t.Errorf("expected a retyped flag for Timeout, got %+v", c)
+	}
+	if c := changes[4]; c.Kind != SpecRemoved || c.Field != "Legacy" || c.Env != "LEGACY" || c.NewType != "" {
+		t.Errorf("expected a removed environment variable for Legacy, got %+v", c)
+	}
+}
+
+func TestCompareSpecsUnchanged(t *testing.T) {
+	type spec struct {
+		Host string
+		Port int
+	}
+	changes, err := CompareSpecs(&spec{}, &spec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+	if _, err := CompareSpecs(spec{}, &spec{}); err == nil {
+		t.Error("expected an error for a specification that is not a pointer")
+	}
+}
+
+func TestChangeKindString(t *testing.T) {
+	for kind, want := range map[ChangeKind]string{
+		SpecAdded:     "added",
+		SpecRemoved:   "removed",
+		SpecRetyped:   "retyped",
+		ChangeKind(9): "ChangeKind(9)",
+	} {
+		if got := kind.String(); got != want {
+			t.Errorf("expected '%s', got '%s'", want, got)
+		}
+	}
+}
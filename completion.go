@@ -0,0 +1,140 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var nonIdentifierRegexp = regexp.MustCompile("[^A-Za-z0-9_]")
+
+// GenerateCompletion returns a minimal completion script for the given
+// shell, either "bash" or "zsh", that completes the long and short flags
+// generated for the configSpecification using the same prefix and options
+// as passed to AddConfiguration. The values of flags with a `choices` tag,
+// or of a registered enum type, are also completed.
+func GenerateCompletion(configSpecification interface{}, prefix string, options ProcessingOptions, shell string) (string, error) {
+	fields, err := describeFields(configSpecification, prefix, options)
+	if err != nil {
+		return "", err
+	}
+
+	var flags []*field
+	for _, f := range fields {
+		if f.long != "" && f.supported() {
+			flags = append(flags, f)
+		}
+	}
+
+	name := path.Base(os.Args[0])
+	switch shell {
+	case "bash":
+		return bashCompletion(name, flags), nil
+	case "zsh":
+		return zshCompletion(name, flags), nil
+	}
+	return "", fmt.Errorf("unsupported shell '%s', must be one of 'bash' or 'zsh'", shell)
+}
+
+// completionValues returns the values allowed for the field, i.e. those of
+// its `choices` tag or the names of its registered enum type, or nil if
+// any value is allowed
+func completionValues(f *field) []string {
+	if choices := f.choices(); choices != nil {
+		return choices
+	}
+	if e, ok := lookupEnum(f.typ); ok {
+		return e.names
+	}
+	return nil
+}
+
+// bashCompletion generates a bash completion script that offers the flag
+// names as completions for the named program and the allowed values, if
+// any, as completions for the word following a flag
+func bashCompletion(name string, flags []*field) string {
+	escape := strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "$", "\\$", "`", "\\`")
+
+	var words []string
+	var cases strings.Builder
+	for _, f := range flags {
+		pattern := "--" + f.long
+		words = append(words, "--"+f.long)
+		if f.short != "" {
+			pattern += "|-" + f.short
+			words = append(words, "-"+f.short)
+		}
+		if values := completionValues(f); values != nil {
+			fmt.Fprintf(&cases, "        %s)\n", pattern)
+			fmt.Fprintf(&cases, "            COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", escape.Replace(strings.Join(values, " ")))
+			fmt.Fprintf(&cases, "            return\n")
+			fmt.Fprintf(&cases, "            ;;\n")
+		}
+	}
+
+	fn := "_" + nonIdentifierRegexp.ReplaceAllString(name, "_") + "_completions"
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bash completion for %s\n", name)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	fmt.Fprintf(&b, "    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	if cases.Len() > 0 {
+		fmt.Fprintf(&b, "    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+		fmt.Fprintf(&b, "    case \"$prev\" in\n")
+		b.WriteString(cases.String())
+		fmt.Fprintf(&b, "    esac\n")
+	}
+	fmt.Fprintf(&b, "    COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(words, " "))
+	fmt.Fprintf(&b, "}\n")
+	fmt.Fprintf(&b, "complete -F %s %s\n", fn, name)
+	return b.String()
+}
+
+// zshCompletion generates a zsh completion script, using _arguments, that
+// offers the flag names, with their help, as completions for the named
+// program and the allowed values, if any, as completions for their
+// arguments
+func zshCompletion(name string, flags []*field) string {
+	escape := strings.NewReplacer("'", "'\\''", "[", "\\[", "]", "\\]", ":", "\\:")
+	escapeValue := strings.NewReplacer("'", "'\\''", "\\", "\\\\", " ", "\\ ", "(", "\\(", ")", "\\)", ":", "\\:")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n", name)
+	fmt.Fprintf(&b, "_arguments")
+	for _, f := range flags {
+		arg := ""
+		if values := completionValues(f); values != nil {
+			escaped := make([]string, len(values))
+			for i, value := range values {
+				escaped[i] = escapeValue.Replace(value)
+			}
+			arg = ":value:(" + strings.Join(escaped, " ") + ")"
+		} else if f.typ.Kind() != reflect.Bool {
+			arg = ":value:"
+		}
+		help := escape.Replace(f.help)
+		fmt.Fprintf(&b, " \\\n    '--%s[%s]%s'", f.long, help, arg)
+		if f.short != "" {
+			fmt.Fprintf(&b, " \\\n    '-%s[%s]%s'", f.short, help, arg)
+		}
+	}
+	fmt.Fprintf(&b, "\n")
+	return b.String()
+}
@@ -0,0 +1,101 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"strings"
+	"testing"
+)
+
+type completionSpec struct {
+	Level   testLevel `short:"l" help:"the log level"`
+	Verbose bool      `help:"verbose output"`
+	Name    string    `long:"name" help:"the name"`
+	Format  string    `choices:"json, yaml" help:"the output format"`
+}
+
+func TestGenerateCompletionBash(t *testing.T) {
+	script, err := GenerateCompletion(&completionSpec{}, "", DefaultOptions, "bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`COMPREPLY=($(compgen -W "--level -l --verbose --name --format" -- "$cur"))`,
+		"complete -F _venom_test_completions venom.test\n",
+		"        --level|-l)\n            COMPREPLY=($(compgen -W \"debug info warn\" -- \"$cur\"))\n            return\n",
+		"        --format)\n            COMPREPLY=($(compgen -W \"json yaml\" -- \"$cur\"))\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected the script to contain %q, got:\n%s", want, script)
+		}
+	}
+	for _, flag := range []string{"--verbose)", "--name)"} {
+		if strings.Contains(script, flag) {
+			t.Errorf("expected no value completion for '%s', got:\n%s", flag, script)
+		}
+	}
+}
+
+func TestGenerateCompletionZsh(t *testing.T) {
+	script, err := GenerateCompletion(&completionSpec{}, "", DefaultOptions, "zsh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`'--level[the log level]:value:(debug info warn)'`,
+		`'-l[the log level]:value:(debug info warn)'`,
+		`'--verbose[verbose output]'`,
+		`'--name[the name]:value:'`,
+		`'--format[the output format]:value:(json yaml)'`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected the script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestGenerateCompletionOptions(t *testing.T) {
+	options := DefaultOptions
+	options.Flags = GenerateEnv
+	script, err := GenerateCompletion(&completionSpec{}, "APP", options, "bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `compgen -W "--name" -- "$cur"`; !strings.Contains(script, want) {
+		t.Errorf("expected only the tagged flag to be completed, got:\n%s", script)
+	}
+
+	options = DefaultOptions
+	options.LongSeparator = "."
+	var c struct {
+		LogLevel string
+	}
+	script, err = GenerateCompletion(&c, "", options, "bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `compgen -W "--log.level" -- "$cur"`; !strings.Contains(script, want) {
+		t.Errorf("expected the flag to use the long separator, got:\n%s", script)
+	}
+}
+
+func TestGenerateCompletionUnsupportedShell(t *testing.T) {
+	if _, err := GenerateCompletion(&completionSpec{}, "", DefaultOptions, "fish"); err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+}
@@ -0,0 +1,35 @@
+//go:build go1.15
+// +build go1.15
+
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import "strconv"
+
+// parseComplex parses a complex number, e.g. `1+2i`, with the given number
+// of bits
+func parseComplex(value string, bits int) (complex128, error) {
+	return strconv.ParseComplex(value, bits)
+}
+
+// formatComplex renders a complex number without the parentheses added by
+// strconv.FormatComplex, e.g. `1+2i`, as it would be specified in a default
+// tag
+func formatComplex(c complex128) string {
+	formatted := strconv.FormatComplex(c, 'g', -1, 128)
+	return formatted[1 : len(formatted)-1]
+}
@@ -0,0 +1,89 @@
+//go:build !go1.15
+// +build !go1.15
+
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseComplex parses a complex number, e.g. `1+2i`, with the given number
+// of bits. Before Go 1.15 strconv cannot parse a complex number, so the
+// real and imaginary parts, either of which may be omitted, are parsed as
+// floats.
+func parseComplex(value string, bits int) (complex128, error) {
+	syntaxError := &strconv.NumError{Func: "ParseComplex", Num: value, Err: strconv.ErrSyntax}
+	s := value
+	if len(s) >= 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		s = s[1 : len(s)-1]
+	}
+	if s == "" {
+		return 0, syntaxError
+	}
+
+	floatBits := 64
+	if bits == 64 {
+		floatBits = 32
+	}
+	parse := func(part string) (float64, error) {
+		f, err := strconv.ParseFloat(part, floatBits)
+		if err != nil {
+			if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+				return f, &strconv.NumError{Func: "ParseComplex", Num: value, Err: strconv.ErrRange}
+			}
+			return 0, syntaxError
+		}
+		return f, nil
+	}
+
+	if !strings.HasSuffix(s, "i") {
+		re, err := parse(s)
+		return complex(re, 0), err
+	}
+	s = s[:len(s)-1]
+
+	// The imaginary part starts at the last sign that is neither leading
+	// nor part of an exponent
+	split := 0
+	for i := len(s) - 1; i > 0; i-- {
+		if (s[i] == '+' || s[i] == '-') && s[i-1] != 'e' && s[i-1] != 'E' {
+			split = i
+			break
+		}
+	}
+	re := 0.0
+	if split > 0 {
+		var err error
+		if re, err = parse(s[:split]); err != nil {
+			return 0, err
+		}
+	}
+	im, err := parse(s[split:])
+	return complex(re, im), err
+}
+
+// formatComplex renders a complex number, e.g. `1+2i`, as it would be
+// specified in a default tag
+func formatComplex(c complex128) string {
+	im := strconv.FormatFloat(imag(c), 'g', -1, 64)
+	if im[0] != '+' && im[0] != '-' {
+		im = "+" + im
+	}
+	return strconv.FormatFloat(real(c), 'g', -1, 64) + im + "i"
+}
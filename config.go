@@ -0,0 +1,50 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// BindConfigFile reads the configuration file at the given path into the
+// viper instance to which a configuration specification was bound, e.g. by
+// AddConfigurationTo, so that its values are resolved below flags and
+// environment variables but above defaults. The file's keys are matched, case
+// insensitively, to the keys derived from the specification, e.g. `server:
+// {listenPort: 80}` sets the `ListenPort` member of the `Server` member.
+//
+// An error is returned if the file at the given path cannot be read. If the
+// path is empty the file is instead discovered using the name and search
+// paths configured on the viper instance, e.g. using v.SetConfigName and
+// v.AddConfigPath, and it is not an error if no file is found.
+func BindConfigFile(v *viper.Viper, path string) error {
+	if path == "" {
+		var notFound viper.ConfigFileNotFoundError
+		if err := v.ReadInConfig(); err != nil && !errors.As(err, &notFound) {
+			return fmt.Errorf("config file '%s': %w", v.ConfigFileUsed(), err)
+		}
+		return nil
+	}
+
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("config file '%s': %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+type fileSpec struct {
+	Host   string `default:"localhost"`
+	Port   int    `default:"80"`
+	Server struct {
+		Name string `default:"web"`
+	}
+}
+
+func TestWithConfigReader(t *testing.T) {
+	os.Setenv("CFG_PORT", "9000")
+	defer os.Unsetenv("CFG_PORT")
+
+	var c fileSpec
+	p, err := New(&c, WithDefault, WithPrefix("CFG"), WithViper(viper.New()),
+		WithConfigReader(strings.NewReader(`{"host": "example.com", "port": 8080, "server": {"name": "api"}}`), "json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Host != "example.com" || c.Server.Name != "api" {
+		t.Errorf("expected the values to be read from the reader, got %+v", c)
+	}
+	if c.Port != 9000 {
+		t.Errorf("expected the environment to take precedence over the reader, got Port %d", c.Port)
+	}
+}
+
+func TestWithConfigReaderInvalid(t *testing.T) {
+	var c fileSpec
+	_, err := New(&c, WithDefault, WithViper(viper.New()), WithConfigReader(strings.NewReader("host: [unterminated"), "yaml"))
+	if err == nil {
+		t.Error("expected an error for a configuration that cannot be parsed")
+	}
+}
+
+// writeConfigFile writes the contents to a file named name in a temporary
+// directory that is removed when the test completes
+func writeConfigFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "venom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWithConfigFiles(t *testing.T) {
+	base := writeConfigFile(t, "defaults.yaml", "host: base.local\nport: 81\nserver:\n  name: base\n")
+	prod := writeConfigFile(t, "prod.yaml", "port: 443\n")
+
+	var c fileSpec
+	p, err := New(&c, WithDefault, WithViper(viper.New()), WithConfigFiles(base, prod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Host != "base.local" || c.Port != 443 || c.Server.Name != "base" {
+		t.Errorf("expected the later file to be merged over the earlier one, got %+v", c)
+	}
+}
+
+func TestWithConfigFilesOverReader(t *testing.T) {
+	override := writeConfigFile(t, "override.yaml", "port: 8443\n")
+
+	var c fileSpec
+	p, err := New(&c, WithDefault, WithViper(viper.New()),
+		WithConfigReader(strings.NewReader("host: reader.local\nport: 1\n"), "yaml"), WithConfigFiles(override))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Host != "reader.local" || c.Port != 8443 {
+		t.Errorf("expected the file to be merged over the reader, got %+v", c)
+	}
+}
+
+func TestWithConfigFilesMissing(t *testing.T) {
+	var c fileSpec
+	_, err := New(&c, WithDefault, WithViper(viper.New()), WithConfigFiles("/does/not/exist.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "config file '/does/not/exist.yaml'") {
+		t.Errorf("expected an error naming the missing file, got '%v'", err)
+	}
+}
@@ -0,0 +1,89 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestNewNamedConfiguration(t *testing.T) {
+	var c struct {
+		NamedConfigPort int `default:"80"`
+	}
+	flagSet, err := NewNamedConfiguration("plugin", &c, "", DefaultOptions, []string{"/usr/bin/host-program"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	flagSet.SetOutput(&out)
+	if err := flagSet.Parse([]string{"--help"}); err != pflag.ErrHelp {
+		t.Fatalf("expected pflag.ErrHelp, got '%v'", err)
+	}
+	if !strings.Contains(out.String(), "Usage of plugin:") {
+		t.Errorf("expected the usage to name the flag set 'plugin', got:\n%s", out.String())
+	}
+	if flagSet.Lookup("named-config-port") == nil {
+		t.Error("expected the flag '--named-config-port' to be defined")
+	}
+}
+
+func TestNewConfigurationNamedAfterProgram(t *testing.T) {
+	var c struct {
+		NamedConfigHost string
+	}
+	flagSet, err := NewConfiguration(&c, "", DefaultOptions, []string{"/usr/bin/host-program"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	flagSet.SetOutput(&out)
+	_ = flagSet.Parse([]string{"--help"})
+	if !strings.Contains(out.String(), "Usage of host-program:") {
+		t.Errorf("expected the usage to name the program, got:\n%s", out.String())
+	}
+}
+
+func TestAddConfigurationToIsolatesInstances(t *testing.T) {
+	type spec struct {
+		IsolatedHost string `default:"shared"`
+	}
+	first, second := viper.New(), viper.New()
+	if err := AddConfigurationTo(first, pflag.NewFlagSet("first", pflag.ContinueOnError), &spec{}, "", DefaultOptions, nil); err != nil {
+		t.Fatal(err)
+	}
+	flagSet := pflag.NewFlagSet("second", pflag.ContinueOnError)
+	if err := AddConfigurationTo(second, flagSet, &spec{}, "", DefaultOptions, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := flagSet.Parse([]string{"--isolated-host=second"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := first.GetString("isolatedhost"); got != "shared" {
+		t.Errorf("expected the first instance to keep its default, got '%s'", got)
+	}
+	if got := second.GetString("isolatedhost"); got != "second" {
+		t.Errorf("expected the second instance to read its flag, got '%s'", got)
+	}
+	if viper.IsSet("isolatedhost") {
+		t.Error("expected nothing to be bound to the global viper instance")
+	}
+}
@@ -0,0 +1,193 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// isNumericKind returns true if the kind is an integer, unsigned integer,
+// or floating point kind
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// compareBound compares a numeric value to a bound, returning -1, 0, or 1
+// if the value is less than, equal to, or greater than the bound
+func compareBound(value reflect.Value, bound string) (int, error) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b, err := strconv.ParseInt(bound, 0, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compareOrdered(value.Int() < b, value.Int() > b), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b, err := strconv.ParseUint(bound, 0, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compareOrdered(value.Uint() < b, value.Uint() > b), nil
+	case reflect.Float32, reflect.Float64:
+		b, err := strconv.ParseFloat(bound, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compareOrdered(value.Float() < b, value.Float() > b), nil
+	}
+	return 0, fmt.Errorf("unsupported type '%s'", value.Type())
+}
+
+// compareOrdered returns the result of a comparison given whether the
+// value is less than or greater than the value to which it was compared
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// choices returns the values allowed for a string field, as specified by
+// its `choices` tag, or nil if any value is allowed
+func (f *field) choices() []string {
+	tag := f.tag.Get("choices")
+	if tag == "" {
+		return nil
+	}
+	choices := strings.Split(tag, ",")
+	for i := range choices {
+		choices[i] = strings.TrimSpace(choices[i])
+	}
+	return choices
+}
+
+// checkConstraints returns an error if the `min`, `max`, or `choices` tags
+// of the field are not valid for its type. The range of duration fields is
+// checked by durationRange and the maximum of count fields by countLimit.
+func (f *field) checkConstraints() error {
+	if f.typ == durationType {
+		_, _, _, _, err := f.durationRange()
+		return err
+	}
+	if !f.isCount() {
+		for _, name := range []string{"min", "max"} {
+			bound := f.tag.Get(name)
+			if bound == "" {
+				continue
+			}
+			if !isNumericKind(f.typ.Kind()) {
+				return fmt.Errorf("%s is only valid for numeric and duration fields", name)
+			}
+			if _, err := compareBound(reflect.Zero(f.typ), bound); err != nil {
+				return fmt.Errorf("invalid %s '%s' for type '%s'", name, bound, f.typ)
+			}
+		}
+	}
+	if f.choices() != nil && f.typ.Kind() != reflect.String {
+		return fmt.Errorf("choices is only valid for string fields")
+	}
+	return nil
+}
+
+// validateConstraints checks the value of the field against its `min`,
+// `max`, and `choices` tags
+func validateConstraints(f *field, value reflect.Value) Errors {
+	if f.typ == durationType {
+		if err := validateDurationRange(f, value); err != nil {
+			return Errors{err}
+		}
+		return nil
+	}
+	if err := f.checkConstraints(); err != nil {
+		return Errors{configError(f, "", err)}
+	}
+
+	var errs Errors
+	if isNumericKind(f.typ.Kind()) && !f.isCount() {
+		rendered := formatValue(f, value)
+		if min := f.tag.Get("min"); min != "" {
+			if cmp, _ := compareBound(value, min); cmp < 0 {
+				errs = append(errs, configErrorf(f, "min", "value '%s' is less than the minimum '%s'", rendered, min))
+			}
+		}
+		if max := f.tag.Get("max"); max != "" {
+			if cmp, _ := compareBound(value, max); cmp > 0 {
+				errs = append(errs, configErrorf(f, "max", "value '%s' is greater than the maximum '%s'", rendered, max))
+			}
+		}
+	}
+	if choices := f.choices(); choices != nil {
+		allowed := false
+		for _, choice := range choices {
+			if value.String() == choice {
+				allowed = true
+			}
+		}
+		if !allowed {
+			errs = append(errs, configErrorf(f, "choices", "value '%s' is not one of '%s'", value.String(), strings.Join(choices, "', '")))
+		}
+	}
+	return errs
+}
+
+// ValidateConstraints checks the value resolved by viper's global instance
+// for each member of the specified configSpecification interface against
+// the member's `min`, `max`, and `choices` tags, using the keys derived by
+// AddConfiguration with the same prefix and options. The errors for all
+// violations, and for constraints that are not valid for the type of their
+// member, are returned as Errors.
+func ValidateConstraints(configSpecification interface{}, prefix string, options ProcessingOptions) error {
+	return ValidateConstraintsFrom(viper.GetViper(), configSpecification, prefix, options)
+}
+
+// ValidateConstraintsFrom is as ValidateConstraints, but checks the values
+// resolved by the given viper instance, as passed to AddConfigurationTo,
+// rather than viper's global instance.
+func ValidateConstraintsFrom(v *viper.Viper, configSpecification interface{}, prefix string, options ProcessingOptions) error {
+	fields, err := describeFields(configSpecification, prefix, options)
+	if err != nil {
+		return err
+	}
+
+	p := &Processor{options: options, viper: v, fields: fields}
+	var errs Errors
+	for _, f := range fields {
+		if f.isRaw() || !f.parseable() {
+			continue
+		}
+		value := reflect.New(f.typ).Elem()
+		if err := p.applyField(p.resolve, f, value); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, validateConstraints(f, value)...)
+	}
+	return errs.errorOrNil()
+}
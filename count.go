@@ -0,0 +1,64 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// isCount returns true if the field is bound to a count flag, i.e. one
+// that is incremented each time it is specified, e.g. -vvv
+func (f *field) isCount() bool {
+	return isTrue(f.tag.Get("count"))
+}
+
+// countLimit returns the maximum of a count field, as specified by the
+// `max` tag, or -1 if there is no maximum, and whether a count that exceeds
+// the maximum is clamped, the default, rather than an error, as specified
+// by the `countOverflow` tag
+func (f *field) countLimit() (int, bool, error) {
+	limit := -1
+	if tag := f.tag.Get("max"); tag != "" {
+		var err error
+		if limit, err = strconv.Atoi(tag); err != nil || limit < 0 {
+			return 0, false, fmt.Errorf("invalid max '%s', must be a non-negative integer", tag)
+		}
+	}
+
+	switch overflow := f.tag.Get("countOverflow"); overflow {
+	case "", "clamp":
+		return limit, true, nil
+	case "error":
+		return limit, false, nil
+	default:
+		return 0, false, fmt.Errorf("invalid countOverflow '%s', must be one of 'clamp' or 'error'", overflow)
+	}
+}
+
+// limitCount applies the maximum of a count field to the value, either
+// clamping the value or returning an error if it exceeds the maximum
+func (f *field) limitCount(val reflect.Value) (reflect.Value, error) {
+	limit, clamp, err := f.countLimit()
+	if err != nil || limit < 0 || val.Int() <= int64(limit) {
+		return val, err
+	}
+	if !clamp {
+		return val, fmt.Errorf("count %d exceeds the maximum of %d", val.Int(), limit)
+	}
+	return reflect.ValueOf(limit).Convert(f.typ), nil
+}
@@ -0,0 +1,119 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestPopulateCount(t *testing.T) {
+	type spec struct {
+		Verbose int `short:"v" count:"true" max:"4"`
+	}
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{want: 0},
+		{args: []string{"-v"}, want: 1},
+		{args: []string{"-vvv"}, want: 3},
+		{args: []string{"-vv", "--verbose"}, want: 3},
+		{args: []string{"-vvvvvv"}, want: 4},
+	}
+	for _, test := range tests {
+		v := viper.New()
+		flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		var c spec
+		if err := AddConfigurationTo(v, flagSet, &c, "", DefaultOptions, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := flagSet.Parse(test.args); err != nil {
+			t.Fatal(err)
+		}
+		if err := PopulateFrom(v, &c, "", DefaultOptions); err != nil {
+			t.Fatal(err)
+		}
+		if c.Verbose != test.want {
+			t.Errorf("%v: expected Verbose to be %d, got %d", test.args, test.want, c.Verbose)
+		}
+	}
+}
+
+func TestCountOverflow(t *testing.T) {
+	tests := []struct {
+		name string
+		spec interface{}
+		args []string
+		want int
+		err  string
+	}{
+		{name: "from default", spec: &struct {
+			Verbose int `short:"v" count:"true" default:"1"`
+		}{}, args: []string{"-vv"}, want: 3},
+		{name: "clamp", spec: &struct {
+			Verbose int `short:"v" count:"true" max:"2"`
+		}{}, args: []string{"-vvvv"}, want: 2},
+		{name: "within the maximum", spec: &struct {
+			Verbose int `short:"v" count:"true" max:"2" countOverflow:"error"`
+		}{}, args: []string{"-vv"}, want: 2},
+		{name: "error", spec: &struct {
+			Verbose int `short:"v" count:"true" max:"2" countOverflow:"error"`
+		}{}, args: []string{"-vvv"}, err: "count 3 exceeds the maximum of 2"},
+		{name: "invalid max", spec: &struct {
+			Verbose int `count:"true" max:"-1"`
+		}{}, err: "invalid max '-1'"},
+		{name: "invalid overflow", spec: &struct {
+			Verbose int `count:"true" countOverflow:"wrap"`
+		}{}, err: "invalid countOverflow 'wrap'"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := New(test.spec, WithDefault, WithViper(viper.New()))
+			if err == nil {
+				if err = p.Parse(test.args); err != nil {
+					t.Fatal(err)
+				}
+				err = p.Apply()
+			}
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("expected an error containing '%s', got '%v'", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reflect.ValueOf(test.spec).Elem().Field(0).Int(); got != int64(test.want) {
+				t.Errorf("expected Verbose to be %d, got %d", test.want, got)
+			}
+		})
+	}
+}
+
+func TestCountRequiresInt(t *testing.T) {
+	var c struct {
+		Verbose string `count:"true"`
+	}
+	if _, err := New(&c, WithDefault, WithViper(viper.New())); err == nil {
+		t.Error("expected an error for a count field that is not an int")
+	}
+}
@@ -0,0 +1,87 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DecryptorFunc decrypts a value that was encrypted at rest, returning the
+// plaintext
+type DecryptorFunc func(ciphertext string) (string, error)
+
+var (
+	decryptorsMu sync.RWMutex
+	decryptors   = map[string]DecryptorFunc{}
+)
+
+// RegisterDecryptor registers a named decryptor that can be referenced
+// from a `decrypt` tag, e.g. `decrypt:"age"`. No decryptors are registered
+// by default. Registering a decryptor with the same name as an existing
+// decryptor replaces it.
+func RegisterDecryptor(name string, fn DecryptorFunc) {
+	decryptorsMu.Lock()
+	defer decryptorsMu.Unlock()
+	decryptors[name] = fn
+}
+
+// lookupDecryptor returns the decryptor registered with the given name
+func lookupDecryptor(name string) (DecryptorFunc, bool) {
+	decryptorsMu.RLock()
+	defer decryptorsMu.RUnlock()
+	fn, ok := decryptors[name]
+	return fn, ok
+}
+
+// checkDecrypt returns an error if the field's `decrypt` tag is specified
+// for a field that is not a secret string
+func (f *field) checkDecrypt() error {
+	if f.tag.Get("decrypt") == "" {
+		return nil
+	}
+	if f.typ.Kind() != reflect.String {
+		return fmt.Errorf("decrypt is only valid for string fields")
+	}
+	if !f.isSecret() {
+		return fmt.Errorf("decrypt is only valid for fields tagged as secret")
+	}
+	return nil
+}
+
+// decrypt decrypts the resolved value of the field using the decryptor
+// referenced by its `decrypt` tag. Values of fields without the tag, and
+// empty values, are returned unchanged.
+func (f *field) decrypt(raw interface{}) (interface{}, error) {
+	name := f.tag.Get("decrypt")
+	if name == "" {
+		return raw, nil
+	}
+	ciphertext := fmt.Sprint(raw)
+	if ciphertext == "" {
+		return raw, nil
+	}
+	fn, ok := lookupDecryptor(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown decryptor '%s'", name)
+	}
+	plaintext, err := fn(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("decryptor '%s': %w", name, err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,139 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+var errCorrupt = errors.New("corrupt")
+
+func init() {
+	// A fake decryptor that reverses the ciphertext, failing for values
+	// that are not marked as encrypted
+	RegisterDecryptor("test-reverse", func(ciphertext string) (string, error) {
+		if !strings.HasPrefix(ciphertext, "enc:") {
+			return "", errCorrupt
+		}
+		r := []rune(strings.TrimPrefix(ciphertext, "enc:"))
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return string(r), nil
+	})
+}
+
+func TestDecrypt(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		env  *string
+		want string
+		err  error
+	}{
+		{"encrypted", stringPtr("enc:2retnuh"), "hunter2", nil},
+		{"empty", stringPtr(""), "", nil},
+		{"unset", nil, "", nil},
+		{"corrupt", stringPtr("plain"), "", errCorrupt},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.env != nil {
+				os.Setenv("APP_PASSWORD", *tc.env)
+				defer os.Unsetenv("APP_PASSWORD")
+			}
+			var c struct {
+				Password string `secret:"true" decrypt:"test-reverse"`
+			}
+			p, err := New(&c, WithDefault, WithPrefix("APP"), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(nil); err != nil {
+				t.Fatal(err)
+			}
+			err = p.Apply()
+			if tc.err != nil {
+				if !errors.Is(firstError(err), tc.err) {
+					t.Fatalf("expected the decryptor error, got '%v'", err)
+				}
+				if !strings.Contains(err.Error(), "decryptor 'test-reverse'") {
+					t.Errorf("expected the error to name the decryptor, got '%v'", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Password != tc.want {
+				t.Errorf("expected Password to be '%s', got '%s'", tc.want, c.Password)
+			}
+		})
+	}
+}
+
+func TestDecryptUnknown(t *testing.T) {
+	os.Setenv("APP_PASSWORD", "enc:x")
+	defer os.Unsetenv("APP_PASSWORD")
+	var c struct {
+		Password string `secret:"true" decrypt:"test-missing"`
+	}
+	p, err := New(&c, WithDefault, WithPrefix("APP"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err == nil || !strings.Contains(err.Error(), "unknown decryptor 'test-missing'") {
+		t.Errorf("expected an unknown decryptor error, got '%v'", err)
+	}
+}
+
+func TestDecryptInvalidFields(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		spec interface{}
+		want string
+	}{
+		{"not secret", &struct {
+			Password string `decrypt:"test-reverse"`
+		}{}, "decrypt is only valid for fields tagged as secret"},
+		{"not string", &struct {
+			Pin int `secret:"true" decrypt:"test-reverse"`
+		}{}, "decrypt is only valid for string fields"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(tc.spec, WithDefault, WithViper(viper.New()))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected the error '%s', got '%v'", tc.want, err)
+			}
+			issues, err := Lint(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			found := false
+			for _, issue := range issues {
+				found = found || (issue.Severity == LintError && strings.Contains(issue.Message, tc.want))
+			}
+			if !found {
+				t.Errorf("expected Lint to report '%s', got %v", tc.want, issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestDefaults(t *testing.T) {
+	var c struct {
+		Host    string        `default:"localhost"`
+		Timeout time.Duration `default:"5s"`
+		Retries int
+		Cache   string `ignored:"true"`
+		Server  struct {
+			Backoff []time.Duration `default:"1s,2s"`
+		}
+	}
+	before := viper.AllKeys()
+
+	defaults, err := Defaults(&c, "APP", DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"host":           "localhost",
+		"timeout":        5 * time.Second,
+		"retries":        0,
+		"server.backoff": []time.Duration{time.Second, 2 * time.Second},
+	}
+	if !reflect.DeepEqual(defaults, want) {
+		t.Errorf("expected the defaults %v, got %v", want, defaults)
+	}
+	if after := viper.AllKeys(); len(after) != len(before) {
+		t.Errorf("expected nothing to be registered with viper, got %v", after)
+	}
+	if c.Host != "" {
+		t.Errorf("expected the specification not to be modified, got Host '%s'", c.Host)
+	}
+}
+
+func TestDefaultsInvalid(t *testing.T) {
+	var c struct {
+		Port int `default:"http"`
+	}
+	if _, err := Defaults(&c, "", DefaultOptions); err == nil {
+		t.Error("expected an error for a default that cannot be parsed")
+	}
+}
+
+func TestGenerateDefaultsYAML(t *testing.T) {
+	var c struct {
+		Host     string        `default:"localhost"`
+		Timeout  time.Duration `default:"1m30s"`
+		Password string        `default:"hunter2" secret:"true"`
+		Server   struct {
+			Port int      `default:"8080"`
+			Tags []string `default:"a,b"`
+		}
+	}
+	data, err := GenerateDefaultsYAML(&c, DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `host: localhost
+server:
+  port: 8080
+  tags:
+  - a
+  - b
+timeout: 1m30s
+`
+	if string(data) != want {
+		t.Errorf("expected the YAML:\n%s\ngot:\n%s", want, data)
+	}
+
+	// The document is loaded by viper as a configuration file
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.MergeConfig(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.GetInt("server.port"); got != 8080 {
+		t.Errorf("expected viper to read 'server.port' as 8080, got %d", got)
+	}
+}
+
+func TestDefaultsFunc(t *testing.T) {
+	var c struct {
+		Host   string `default:"localhost"`
+		Server struct {
+			Port    int `default:"80"`
+			Retries int `default:"3"`
+		}
+	}
+	var paths []string
+	p, err := New(&c, WithDefault, WithViper(viper.New()),
+		WithDefaultsFunc(func(path []string) (string, bool) {
+			paths = append(paths, strings.Join(path, "."))
+			if strings.Join(path, ".") == "Server.Port" {
+				return "8080", true
+			}
+			return "", false
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Port != 8080 {
+		t.Errorf("expected the provided default 8080, got %d", c.Server.Port)
+	}
+	if c.Host != "localhost" || c.Server.Retries != 3 {
+		t.Errorf("expected the tagged defaults to be kept, got '%s' and %d", c.Host, c.Server.Retries)
+	}
+	for _, want := range []string{"Host", "Server.Port", "Server.Retries"} {
+		found := false
+		for _, path := range paths {
+			found = found || path == want
+		}
+		if !found {
+			t.Errorf("expected the function to be called for '%s', got %v", want, paths)
+		}
+	}
+}
+
+func TestDefaultsFuncInvalid(t *testing.T) {
+	var c struct {
+		Port int `default:"80"`
+	}
+	options := DefaultOptions
+	options.DefaultsFunc = func([]string) (string, bool) {
+		return "http", true
+	}
+	if _, err := Defaults(&c, "", options); err == nil {
+		t.Error("expected an error for a provided default that cannot be parsed")
+	}
+}
+
+type namedPort int
+
+func TestDefaultParseErrors(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		spec interface{}
+		want string
+	}{
+		{"int", &struct {
+			Port int `default:"http"`
+		}{}, "cannot parse default 'http' as int"},
+		{"named", &struct {
+			Port namedPort `default:"http"`
+		}{}, "cannot parse default 'http' as venom.namedPort"},
+		{"uint", &struct {
+			Size uint8 `default:"-1"`
+		}{}, "cannot parse default '-1' as uint8"},
+		{"bool", &struct {
+			Debug bool `default:"maybe"`
+		}{}, "cannot parse default 'maybe' as bool"},
+		{"float", &struct {
+			Ratio float64 `default:"half"`
+		}{}, "cannot parse default 'half' as float64"},
+		{"duration", &struct {
+			Timeout time.Duration `default:"soon"`
+		}{}, "cannot parse default 'soon' as time.Duration"},
+		{"slice", &struct {
+			Ports []int `default:"80,http"`
+		}{}, "cannot parse default '80,http' as []int"},
+		{"map", &struct {
+			Limits map[string]int `default:"a=1,b"`
+		}{}, "cannot parse default 'a=1,b' as map[string]int"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New(test.spec, WithDefault, WithViper(viper.New()))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("expected the error '%s', got '%v'", test.want, err)
+			}
+		})
+	}
+}
+
+func TestSecretDefaultParseError(t *testing.T) {
+	var c struct {
+		Pin int `default:"hunter2" secret:"true"`
+	}
+	_, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err == nil || !strings.Contains(err.Error(), "cannot parse default as int") {
+		t.Fatalf("expected an error naming the type, got '%v'", err)
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("expected the secret default not to be included, got '%v'", err)
+	}
+}
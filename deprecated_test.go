@@ -0,0 +1,100 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestBlankDeprecationRegistersNothing(t *testing.T) {
+	var c struct {
+		Host string
+		Port int `deprecated:""`
+	}
+
+	v := viper.New()
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	err := AddConfigurationTo(v, flagSet, &c, "", DefaultOptions, nil)
+
+	var ce *ConfigError
+	if !errors.As(err, &ce) || ce.Field != "Port" || ce.Tag != "deprecated" {
+		t.Fatalf("expected a deprecated error for 'Port', got %v", err)
+	}
+	flagSet.VisitAll(func(flag *pflag.Flag) {
+		t.Errorf("expected no flags to be registered, found '%s'", flag.Name)
+	})
+	if keys := v.AllKeys(); len(keys) != 0 {
+		t.Errorf("expected no keys to be bound, found %v", keys)
+	}
+}
+
+func TestHiddenAndDeprecatedFlags(t *testing.T) {
+	var c struct {
+		Internal string `hidden:"true"`
+		Old      string `deprecated:"use --new instead"`
+		New      string
+	}
+
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := AddConfigurationTo(viper.New(), flagSet, &c, "", DefaultOptions, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !flagSet.Lookup("internal").Hidden {
+		t.Error("expected --internal to be hidden")
+	}
+	if msg := flagSet.Lookup("old").Deprecated; msg != "use --new instead" {
+		t.Errorf("expected --old to be deprecated with 'use --new instead', got '%s'", msg)
+	}
+	if flag := flagSet.Lookup("new"); flag.Hidden || flag.Deprecated != "" {
+		t.Error("expected --new to be neither hidden nor deprecated")
+	}
+}
+
+func TestDeprecationMessage(t *testing.T) {
+	tests := []struct {
+		tag  reflect.StructTag
+		want string
+	}{
+		{tag: ``, want: ""},
+		{tag: `deprecated:"use --new"`, want: "use --new"},
+		{tag: `deprecatedSince:"v1.2"`, want: "deprecated since v1.2"},
+		{tag: `removeIn:"v2.0" deprecated:"use --new"`, want: "removed in v2.0; use --new"},
+		{tag: `deprecated:"use --new" deprecatedSince:"v1.2" removeIn:"v2.0"`, want: "deprecated since v1.2, removed in v2.0; use --new"},
+	}
+	for _, test := range tests {
+		if got := deprecationMessage(test.tag); got != test.want {
+			t.Errorf("%s: expected '%s', got '%s'", test.tag, test.want, got)
+		}
+	}
+}
+
+func TestDeprecationTagsRegisterFlag(t *testing.T) {
+	var c struct {
+		Old string `deprecatedSince:"v1.2" removeIn:"v2.0"`
+	}
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := AddConfigurationTo(viper.New(), flagSet, &c, "", DefaultOptions, nil); err != nil {
+		t.Fatal(err)
+	}
+	if msg := flagSet.Lookup("old").Deprecated; msg != "deprecated since v1.2, removed in v2.0" {
+		t.Errorf("expected the composed deprecation message, got '%s'", msg)
+	}
+}
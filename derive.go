@@ -0,0 +1,155 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DeriveFunc computes the value of a field from the resolved value of the
+// field it is derived from, e.g. a metrics URL from a base URL. The result
+// is converted to the type of the derived field as a configuration value
+// would be, so it may, for example, be a string that is parsed.
+type DeriveFunc func(value interface{}) (interface{}, error)
+
+var (
+	derivesMu sync.RWMutex
+	derives   = map[string]DeriveFunc{}
+)
+
+// RegisterDerive registers a named function that can be referenced from a
+// `derive` tag, e.g. `derive:"metrics"`, to compute the value of a field
+// from the field named by its `deriveFrom` tag. No functions are
+// registered by default. Registering a function with the same name as an
+// existing function replaces it.
+func RegisterDerive(name string, fn DeriveFunc) {
+	derivesMu.Lock()
+	defer derivesMu.Unlock()
+	derives[name] = fn
+}
+
+// lookupDerive returns the derive function registered with the given name
+func lookupDerive(name string) (DeriveFunc, bool) {
+	derivesMu.RLock()
+	defer derivesMu.RUnlock()
+	fn, ok := derives[name]
+	return fn, ok
+}
+
+// derivation a field whose value, unless explicitly provided, is derived
+// from that of another field, a sibling in the same struct
+type derivation struct {
+	field *field
+	from  *field
+}
+
+// describeDerivations returns the derivations of the given fields, ordered
+// such that a field is derived after the field it is derived from, if that
+// is itself derived. It is an error if a field is derived from an unknown
+// field or, directly or indirectly, from itself.
+func describeDerivations(fields []*field) ([]derivation, error) {
+	from := map[*field]*field{}
+	for _, f := range fields {
+		name := f.tag.Get("deriveFrom")
+		if name == "" {
+			continue
+		}
+		if f.tag.Get("derive") == "" {
+			return nil, configErrorf(f, "deriveFrom", "deriveFrom requires a derive tag naming a function registered with RegisterDerive")
+		}
+		src, ok := sibling(fields, f, name)
+		if !ok {
+			return nil, configErrorf(f, "deriveFrom", "unknown field '%s'", siblingName(f, name))
+		}
+		from[f] = src
+	}
+
+	// Each field is visited after the field it is derived from, so that its
+	// value is derived from the derived value. A field that is reached again
+	// while it is being visited is part of a cycle.
+	var ordered []derivation
+	done := map[*field]bool{}
+	visiting := map[*field]bool{}
+	var visit func(f *field, path []string) error
+	visit = func(f *field, path []string) error {
+		src, derived := from[f]
+		if !derived || done[f] {
+			return nil
+		}
+		path = append(path, f.name)
+		if visiting[f] {
+			return configErrorf(f, "deriveFrom", "cycle in derived fields '%s'", strings.Join(path, "' -> '"))
+		}
+		visiting[f] = true
+		if err := visit(src, path); err != nil {
+			return err
+		}
+		done[f] = true
+		ordered = append(ordered, derivation{field: f, from: src})
+		return nil
+	}
+	for _, f := range fields {
+		if err := visit(f, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// applyDerivations sets each derived field, for which no value, other than
+// a default, was provided by any source, to the value returned by its
+// derive function for the value of the field it is derived from
+func (p *Processor) applyDerivations(specElem reflect.Value) error {
+	if len(p.derivations) == 0 {
+		return nil
+	}
+	file, err := p.fileConfig()
+	if err != nil {
+		return err
+	}
+	for _, d := range p.derivations {
+		f := d.field
+		if p.sourceOf(f, file, allSources) != DefaultSource {
+			continue
+		}
+		name := f.tag.Get("derive")
+		fn, ok := lookupDerive(name)
+		if !ok {
+			return configErrorf(f, "derive", "unknown derive function '%s'", name)
+		}
+		result, err := fn(fieldByIndex(specElem, d.from.index).Interface())
+		if err != nil {
+			return configError(f, "derive", fmt.Errorf("derive '%s': %w", name, err))
+		}
+		if result == nil {
+			continue
+		}
+		val, err := f.decode(result)
+		if err != nil {
+			return configError(f, "derive", fmt.Errorf("derive '%s': %w", name, err))
+		}
+		if f.pointer {
+			ptr := reflect.New(f.typ)
+			ptr.Elem().Set(val)
+			val = ptr
+		}
+		fieldByIndex(specElem, f.index).Set(val)
+	}
+	return nil
+}
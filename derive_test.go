@@ -0,0 +1,141 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func init() {
+	RegisterDerive("test-suffix", func(value interface{}) (interface{}, error) {
+		if value == "" {
+			return nil, nil
+		}
+		return fmt.Sprintf("%s/metrics", value), nil
+	})
+	RegisterDerive("test-port", func(value interface{}) (interface{}, error) {
+		return fmt.Sprintf("%d", value.(int)+1), nil
+	})
+	RegisterDerive("test-fail", func(interface{}) (interface{}, error) {
+		return nil, errors.New("failed")
+	})
+}
+
+type deriveSpec struct {
+	// Health is declared before the field it is derived from to check that
+	// derivations are ordered
+	Health  string `deriveFrom:"Metrics" derive:"test-suffix"`
+	Base    string `default:"http://localhost"`
+	Metrics string `default:"unused" deriveFrom:"Base" derive:"test-suffix"`
+	Server  struct {
+		Port      int `default:"8080"`
+		AdminPort int `deriveFrom:"Port" derive:"test-port"`
+	}
+}
+
+func TestDerive(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		args    []string
+		health  string
+		metrics string
+		admin   int
+	}{
+		{"derived", nil, "http://localhost/metrics/metrics", "http://localhost/metrics", 8081},
+		{"base", []string{"--base=http://api"}, "http://api/metrics/metrics", "http://api/metrics", 8081},
+		{"explicit", []string{"--metrics=http://m", "--server-admin-port=9000"}, "http://m/metrics", "http://m", 9000},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var c deriveSpec
+			p, err := New(&c, WithDefault, WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if c.Metrics != test.metrics || c.Health != test.health {
+				t.Errorf("expected '%s' and '%s', got '%s' and '%s'", test.metrics, test.health, c.Metrics, c.Health)
+			}
+			if c.Server.AdminPort != test.admin {
+				t.Errorf("expected AdminPort to be %d, got %d", test.admin, c.Server.AdminPort)
+			}
+		})
+	}
+}
+
+func TestDeriveInvalid(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		spec interface{}
+		want string
+	}{
+		{"unknown field", &struct {
+			Metrics string `deriveFrom:"Base" derive:"test-suffix"`
+		}{}, "unknown field 'Base'"},
+		{"no function", &struct {
+			Base    string
+			Metrics string `deriveFrom:"Base"`
+		}{}, "deriveFrom requires a derive tag"},
+		{"cycle", &struct {
+			A string `deriveFrom:"B" derive:"test-suffix"`
+			B string `deriveFrom:"A" derive:"test-suffix"`
+		}{}, "cycle in derived fields"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New(test.spec, WithDefault, WithViper(viper.New()))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("expected the error '%s', got '%v'", test.want, err)
+			}
+		})
+	}
+}
+
+func TestDeriveErrors(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		spec interface{}
+		want string
+	}{
+		{"unknown function", &struct {
+			Base    string
+			Metrics string `deriveFrom:"Base" derive:"test-missing"`
+		}{}, "unknown derive function 'test-missing'"},
+		{"failed", &struct {
+			Base    string
+			Metrics string `deriveFrom:"Base" derive:"test-fail"`
+		}{}, "derive 'test-fail': failed"},
+		{"conversion", &struct {
+			Base string `default:"x"`
+			Port int    `deriveFrom:"Base" derive:"test-suffix"`
+		}{}, "derive 'test-suffix'"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := applyArgs(t, test.spec)
+			var cerr *ConfigError
+			if !errors.As(firstError(err), &cerr) || cerr.Tag != "derive" || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("expected a derive error '%s', got '%v'", test.want, err)
+			}
+		})
+	}
+}
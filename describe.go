@@ -0,0 +1,77 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+// FieldBinding describes the bindings derived for a member of a
+// configuration specification
+type FieldBinding struct {
+	// Name the path of the member, e.g. `Server.Port`
+	Name string
+
+	// Key the viper key to which the member is bound
+	Key string
+
+	// Env the environment variable bound to the member, if any
+	Env string
+
+	// Long the long flag bound to the member, if any
+	Long string
+
+	// Short the short flag bound to the member, if any
+	Short string
+
+	// Default the default as specified, i.e. before it is parsed, or
+	// `****` for a member tagged as secret
+	Default string
+
+	// Help the help text of the flag
+	Help string
+
+	// Ignored true if the member is not processed, because it is tagged
+	// `ignored` or is untagged when OnlyTagged is set, or because it
+	// provides the value of another member
+	Ignored bool
+}
+
+// DescribeConfiguration returns a description of the bindings that
+// AddConfiguration derives for each member of the specified
+// configSpecification interface, including ignored members, in declaration
+// order. The flags are only reported for members for which a flag is
+// generated. Nothing is registered with viper or pflag, so this is safe to
+// call for introspection, e.g. to generate documentation.
+func DescribeConfiguration(configSpecification interface{}, prefix string, options ProcessingOptions) ([]FieldBinding, error) {
+	fields, err := describeAllFields(configSpecification, prefix, options)
+	if err != nil {
+		return nil, err
+	}
+
+	bindings := make([]FieldBinding, len(fields))
+	for i, f := range fields {
+		bindings[i] = FieldBinding{
+			Name:    f.name,
+			Key:     f.key,
+			Env:     f.env,
+			Default: f.redactedDefault(),
+			Help:    f.help,
+			Ignored: f.ignored,
+		}
+		if f.long != "" && f.supported() {
+			bindings[i].Long = f.long
+			bindings[i].Short = f.short
+		}
+	}
+	return bindings, nil
+}
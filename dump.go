@@ -0,0 +1,217 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// displayValue returns a representation of the field's value suitable for
+// display, i.e. durations, times, IP addresses, URLs, complex numbers, and
+// bytes are rendered as strings rather than their underlying representation
+func displayValue(f *field, value reflect.Value) interface{} {
+	switch f.typ {
+	case durationType:
+		return value.Interface().(time.Duration).String()
+	case ipType:
+		return value.Interface().(net.IP).String()
+	case ipMaskType:
+		return formatIPMask(value.Interface().(net.IPMask))
+	case urlType:
+		u := value.Interface().(url.URL)
+		return u.String()
+	case timeType:
+		return value.Interface().(time.Time).Format(f.layouts()[0])
+	}
+	if e, ok := lookupEnum(f.typ); ok {
+		return e.name(value.Convert(reflect.TypeOf(int64(0))).Int())
+	}
+	if isTextType(f.typ) {
+		if text, ok := formatText(value); ok {
+			return text
+		}
+	}
+	if kind := f.typ.Kind(); kind == reflect.Complex64 || kind == reflect.Complex128 {
+		return formatComplex(value.Complex())
+	}
+	if isBytesType(f.typ) {
+		return f.formatBytes(value.Bytes())
+	}
+	if f.typ.Kind() == reflect.Slice {
+		elem := f.elem()
+		list := make([]interface{}, value.Len())
+		for i := range list {
+			list[i] = displayValue(elem, value.Index(i))
+		}
+		return list
+	}
+	return value.Interface()
+}
+
+// DumpConfig renders the configuration specification's current values in
+// the given format, either "yaml" or "json", keyed by the viper key of each
+// field. This is typically called after Apply to display the effective
+// configuration.
+func (p *Processor) DumpConfig(format string) ([]byte, error) {
+	specElem := reflect.ValueOf(p.spec).Elem()
+	values := map[string]interface{}{}
+	for _, f := range p.fields {
+		if f.isRaw() {
+			values[f.key] = f.redact(p.raw[f.name])
+			continue
+		}
+		if value, ok := f.value(specElem); ok {
+			values[f.key] = f.redact(displayValue(f, value))
+		} else {
+			values[f.key] = nil
+		}
+	}
+	return marshal(values, format)
+}
+
+// DumpChanged is as DumpConfig, but renders only the fields whose current
+// value differs from their default, e.g. to record the values an operator
+// overrode. Values are compared in the form in which a default is specified,
+// and the values of fields tagged as secret are rendered as `****`.
+func (p *Processor) DumpChanged(format string) ([]byte, error) {
+	specElem := reflect.ValueOf(p.spec).Elem()
+	values := map[string]interface{}{}
+	for _, f := range p.fields {
+		if !f.parseable() {
+			continue
+		}
+		def, err := f.defaultValue()
+		if err != nil {
+			return nil, configError(f, "default", err)
+		}
+
+		var value interface{}
+		if f.isRaw() {
+			if p.raw[f.name] == def {
+				continue
+			}
+			value = p.raw[f.name]
+		} else {
+			// The default of a pointer without a default tag is nil, so
+			// any value is a change
+			current, ok := f.value(specElem)
+			if !ok || (!f.pointer || f.def != "") && formatValue(f, current) == formatValue(f, reflect.ValueOf(def)) {
+				continue
+			}
+			value = displayValue(f, current)
+		}
+		values[f.key] = f.redact(value)
+	}
+	return marshal(values, format)
+}
+
+// GenerateDefaultsYAML returns a YAML document containing the default value
+// of each member of the configSpecification, keyed by the viper key to which
+// it is bound. Keys containing the key delimiter are rendered as nested
+// maps, as viper expects of a configuration file, so that the document can
+// be loaded as a base layer using viper.MergeConfig.
+func GenerateDefaultsYAML(configSpecification interface{}, options ProcessingOptions) ([]byte, error) {
+	fields, err := describeFields(configSpecification, "", options)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	for _, f := range fields {
+		if !f.parseable() || f.isSecret() {
+			continue
+		}
+		value, err := f.defaultValue()
+		if err != nil {
+			return nil, configError(f, "default", err)
+		}
+
+		// Walk, creating as required, the maps enclosing the key
+		m := values
+		path := strings.Split(f.key, options.keyDelimiter())
+		for _, name := range path[:len(path)-1] {
+			child, ok := m[name].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				m[name] = child
+			}
+			m = child
+		}
+		m[path[len(path)-1]] = displayValue(f, reflect.ValueOf(value))
+	}
+	return yaml.Marshal(values)
+}
+
+// ExportResolved returns an environment variable assignment, `NAME=value`,
+// for the current value of each member of the configSpecification bound
+// to an environment variable, e.g. after Apply has been called. The
+// assignments are suitable for the environment of a child process, as
+// the value is rendered in the same form as a default. Nothing is redacted
+// unless the names, e.g. `DB.Password`, of fields to redact are given, in
+// which case their values are rendered as `****`. Nil pointer members are
+// omitted. The assignments are in declaration order unless WithSortedOutput
+// is set.
+func ExportResolved(configSpecification interface{}, prefix string, options ProcessingOptions, redact ...string) ([]string, error) {
+	fields, err := describeFields(configSpecification, prefix, options)
+	if err != nil {
+		return nil, err
+	}
+
+	specElem := reflect.ValueOf(configSpecification).Elem()
+	var env []string
+	for _, f := range fields {
+		if f.env == "" || !isParseableType(f.typ) {
+			continue
+		}
+		current, ok := f.value(specElem)
+		if !ok {
+			continue
+		}
+		value := formatValue(f, current)
+		for _, name := range redact {
+			if name == f.name {
+				value = redacted
+			}
+		}
+		env = append(env, f.env+"="+value)
+	}
+	if options.Flags&WithSortedOutput != 0 {
+		sort.Slice(env, func(i, j int) bool {
+			return strings.SplitN(env[i], "=", 2)[0] < strings.SplitN(env[j], "=", 2)[0]
+		})
+	}
+	return env, nil
+}
+
+// marshal renders the values in the given format
+func marshal(values map[string]interface{}, format string) ([]byte, error) {
+	switch format {
+	case "yaml":
+		return yaml.Marshal(values)
+	case "json":
+		return json.MarshalIndent(values, "", "  ")
+	}
+	return nil, fmt.Errorf("unsupported format '%s', must be one of 'yaml' or 'json'", format)
+}
@@ -0,0 +1,123 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+type dumpSpec struct {
+	Host    string        `default:"localhost"`
+	Timeout time.Duration `default:"30s"`
+	Server  struct {
+		Port int `default:"80"`
+	}
+}
+
+func TestConfigDumpFlag(t *testing.T) {
+	var c dumpSpec
+	var out bytes.Buffer
+	p, err := New(&c, WithDefault, WithConfigDumpFlag("dump-config"), WithOutput(&out), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--dump-config", "--server-port=8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != ErrConfigDump {
+		t.Fatalf("expected ErrConfigDump, got '%v'", err)
+	}
+
+	var dumped map[string]interface{}
+	if err := yaml.Unmarshal(out.Bytes(), &dumped); err != nil {
+		t.Fatalf("expected YAML, got '%s': %s", out.String(), err)
+	}
+	want := map[string]interface{}{"host": "localhost", "timeout": "30s", "server.port": 8080}
+	for key, value := range want {
+		if dumped[key] != value {
+			t.Errorf("expected '%s' to be '%v', got '%v'", key, value, dumped[key])
+		}
+	}
+}
+
+func TestDumpConfigJSON(t *testing.T) {
+	var c dumpSpec
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.FlagSet().Lookup("dump-config") != nil {
+		t.Error("expected no dump flag without WithConfigDumpFlag")
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := p.DumpConfig("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dumped map[string]interface{}
+	if err := json.Unmarshal(data, &dumped); err != nil {
+		t.Fatalf("expected JSON, got '%s': %s", data, err)
+	}
+	if dumped["timeout"] != "30s" || dumped["server.port"] != float64(80) {
+		t.Errorf("expected the resolved values, got %v", dumped)
+	}
+	if _, err := p.DumpConfig("toml"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestDumpChanged(t *testing.T) {
+	var c struct {
+		dumpSpec `squash:"true"`
+		Password string   `secret:"true"`
+		Tags     []string `default:"a,b"`
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--timeout=30s", "--server-port=8080", "--password=hunter2", "--tags=a,b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := p.DumpChanged("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dumped map[string]interface{}
+	if err := json.Unmarshal(data, &dumped); err != nil {
+		t.Fatalf("expected JSON, got '%s': %s", data, err)
+	}
+	want := map[string]interface{}{"server.port": float64(8080), "password": "****"}
+	if len(dumped) != len(want) {
+		t.Errorf("expected only the changed values %v, got %v", want, dumped)
+	}
+	for key, value := range want {
+		if dumped[key] != value {
+			t.Errorf("expected '%s' to be '%v', got '%v'", key, value, dumped[key])
+		}
+	}
+}
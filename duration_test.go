@@ -0,0 +1,224 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestParseExtendedDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		valid bool
+	}{
+		{value: "1d", want: 24 * time.Hour, valid: true},
+		{value: "2w", want: 14 * 24 * time.Hour, valid: true},
+		{value: "1d12h", want: 36 * time.Hour, valid: true},
+		{value: "1.5d", want: 36 * time.Hour, valid: true},
+		{value: "90s", want: 90 * time.Second, valid: true},
+		{value: "-1w", want: -7 * 24 * time.Hour, valid: true},
+		{value: "1y"},
+		{value: "d"},
+	}
+	for _, test := range tests {
+		got, err := parseExtendedDuration(test.value)
+		switch {
+		case !test.valid && err == nil:
+			t.Errorf("%s: expected an error, got %s", test.value, got)
+		case test.valid && err != nil:
+			t.Errorf("%s: unexpected error '%s'", test.value, err)
+		case test.valid && got != test.want:
+			t.Errorf("%s: expected %s, got %s", test.value, test.want, got)
+		}
+	}
+}
+
+func TestWithExtendedDurations(t *testing.T) {
+	type spec struct {
+		Retention time.Duration   `default:"1w"`
+		Windows   []time.Duration `default:"1d,12h"`
+	}
+	os.Setenv("EXT_RETENTION", "2w1d")
+	defer os.Unsetenv("EXT_RETENTION")
+
+	var c spec
+	p, err := New(&c, WithDefault, WithExtendedDurations, WithPrefix("EXT"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--windows=3d"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Retention != 15*24*time.Hour {
+		t.Errorf("expected Retention to be 15 days, got %s", c.Retention)
+	}
+	if len(c.Windows) != 1 || c.Windows[0] != 72*time.Hour {
+		t.Errorf("expected Windows to be [72h], got %v", c.Windows)
+	}
+
+	if _, err := New(&spec{}, WithDefault, WithViper(viper.New())); err == nil {
+		t.Error("expected an error for a day default without WithExtendedDurations")
+	}
+}
+
+func TestExtendedDurationsFromFile(t *testing.T) {
+	var c struct {
+		Retention time.Duration
+	}
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader("retention: 3d\n")); err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(&c, WithDefault, WithExtendedDurations, WithViper(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Retention != 72*time.Hour {
+		t.Errorf("expected Retention to be read from the file as 72h, got %s", c.Retention)
+	}
+}
+
+func TestDurationRange(t *testing.T) {
+	type spec struct {
+		Timeout time.Duration `default:"90s" min:"1s" max:"5m" help:"request timeout"`
+	}
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{},
+		{args: []string{"--timeout=1s"}},
+		{args: []string{"--timeout=5m"}},
+		{args: []string{"--timeout=500ms"}, want: "value '500ms' is less than the minimum '1s'"},
+		{args: []string{"--timeout=1h"}, want: "value '1h0m0s' is greater than the maximum '5m0s'"},
+	}
+	for _, test := range tests {
+		var c spec
+		err := applyArgs(t, &c, test.args...)
+		switch {
+		case test.want == "" && err != nil:
+			t.Errorf("%v: unexpected error '%s'", test.args, err)
+		case test.want != "" && (err == nil || !strings.Contains(err.Error(), test.want)):
+			t.Errorf("%v: expected an error containing '%s', got '%v'", test.args, test.want, err)
+		}
+	}
+
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	viper.Reset()
+	defer viper.Reset()
+	if err := AddConfiguration(flagSet, &spec{}, "", DefaultOptions, nil); err != nil {
+		t.Fatal(err)
+	}
+	if def := flagSet.Lookup("timeout").DefValue; def != "90s" {
+		t.Errorf("expected the default to be shown as '90s', got '%s'", def)
+	}
+}
+
+func TestDurationRangeInvalid(t *testing.T) {
+	var c struct {
+		Timeout time.Duration `min:"soon"`
+	}
+	if _, err := New(&c, WithDefault, WithViper(viper.New())); err == nil || !strings.Contains(err.Error(), "invalid min 'soon'") {
+		t.Errorf("expected an invalid min error, got '%v'", err)
+	}
+}
+
+func TestDurationUnit(t *testing.T) {
+	type spec struct {
+		Timeout time.Duration   `default:"30" unit:"s"`
+		Delays  []time.Duration `default:"100,250" unit:"ms"`
+	}
+	tests := []struct {
+		name    string
+		env     string
+		args    []string
+		file    string
+		timeout time.Duration
+		delays  []time.Duration
+	}{
+		{name: "default", timeout: 30 * time.Second, delays: []time.Duration{100 * time.Millisecond, 250 * time.Millisecond}},
+		{name: "flag", args: []string{"--timeout=45", "--delays=5,1s"}, timeout: 45 * time.Second, delays: []time.Duration{5 * time.Millisecond, time.Second}},
+		{name: "suffix", args: []string{"--timeout=2m"}, timeout: 2 * time.Minute},
+		{name: "env", env: "90", timeout: 90 * time.Second},
+		{name: "file", file: "timeout: 15", timeout: 15 * time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.env != "" {
+				os.Setenv("UNIT_TIMEOUT", test.env)
+				defer os.Unsetenv("UNIT_TIMEOUT")
+			}
+			options := []Option{WithDefault, WithPrefix("UNIT"), WithViper(viper.New())}
+			if test.file != "" {
+				options = append(options, WithConfigReader(strings.NewReader(test.file), "yaml"))
+			}
+			var c spec
+			p, err := New(&c, options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if c.Timeout != test.timeout {
+				t.Errorf("expected Timeout to be '%s', got '%s'", test.timeout, c.Timeout)
+			}
+			if test.delays != nil && fmt.Sprint(c.Delays) != fmt.Sprint(test.delays) {
+				t.Errorf("expected Delays to be %v, got %v", test.delays, c.Delays)
+			}
+		})
+	}
+}
+
+func TestDurationUnitInvalid(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		spec interface{}
+		want string
+	}{
+		{"unknown unit", &struct {
+			Timeout time.Duration `unit:"d"`
+		}{}, "invalid unit 'd'"},
+		{"not a duration", &struct {
+			Port int `unit:"s"`
+		}{}, "unit is only valid for duration fields"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New(test.spec, WithDefault, WithViper(viper.New()))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("expected the error '%s', got '%v'", test.want, err)
+			}
+		})
+	}
+}
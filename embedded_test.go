@@ -0,0 +1,77 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+type Common struct {
+	Verbose bool `default:"true"`
+	secret  string
+}
+
+type Database struct {
+	Host string `default:"db.local"`
+}
+
+func TestEmbeddedStructs(t *testing.T) {
+	var c struct {
+		Common
+		Database `name:"db"`
+		Port     int
+	}
+	fields, err := describeFields(&c, "APP", DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][3]string
+	for _, f := range fields {
+		got = append(got, [3]string{f.name, f.env, f.long})
+	}
+	want := [][3]string{
+		{"Verbose", "APP_VERBOSE", "verbose"},
+		{"Database.Host", "APP_DB_HOST", "db-host"},
+		{"Port", "APP_PORT", "port"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the bindings %v, got %v", want, got)
+	}
+}
+
+func TestEmbeddedStructsApply(t *testing.T) {
+	var c struct {
+		Common
+		Database `name:"db"`
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--db-host=remote"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Verbose || c.Host != "remote" || c.secret != "" {
+		t.Errorf("expected the promoted and prefixed members to be set, got %+v", c)
+	}
+}
@@ -0,0 +1,95 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestEmptyEnvIsTrue(t *testing.T) {
+	type spec struct {
+		Debug bool
+	}
+
+	tests := []struct {
+		name  string
+		env   *string
+		flags Flags
+		args  []string
+		want  bool
+	}{
+		{name: "set empty", env: stringPtr(""), flags: WithEmptyEnvIsTrue, want: true},
+		{name: "set false", env: stringPtr("false"), flags: WithEmptyEnvIsTrue, want: false},
+		{name: "unset", flags: WithEmptyEnvIsTrue, want: false},
+		{name: "set empty without option", env: stringPtr(""), want: false},
+		{name: "set empty with flag false", env: stringPtr(""), flags: WithEmptyEnvIsTrue, args: []string{"--debug=false"}, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Unsetenv("EMPTY_DEBUG")
+			if test.env != nil {
+				os.Setenv("EMPTY_DEBUG", *test.env)
+				defer os.Unsetenv("EMPTY_DEBUG")
+			}
+
+			var c spec
+			p, err := New(&c, WithEnv(), WithFlag(), test.flags, WithPrefix("EMPTY"), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if c.Debug != test.want {
+				t.Errorf("expected Debug to be %t, got %t", test.want, c.Debug)
+			}
+		})
+	}
+}
+
+func TestEmptyEnvIsTrueLeavesDefault(t *testing.T) {
+	type spec struct {
+		Debug bool
+	}
+	os.Setenv("EMPTY_DEBUG", "")
+	defer os.Unsetenv("EMPTY_DEBUG")
+
+	var c spec
+	p, err := New(&c, WithEnv(), WithFlag(), WithEmptyEnvIsTrue, WithPrefix("EMPTY"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def := p.FlagSet().Lookup("debug").DefValue; def != "false" {
+		t.Errorf("expected the flag default to be 'false', got '%s'", def)
+	}
+	sources, err := p.Sources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src := sources["debug"]; src != EnvSource {
+		t.Errorf("expected the source to be '%s', got '%s'", EnvSource, src)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,163 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// enum describes a registered enumerated type, i.e. an integer type with
+// a String method naming each of its values
+type enum struct {
+	names  []string
+	values []int64
+
+	// placeholder, if set, is displayed as the value placeholder in the
+	// usage in place of the names of the values
+	placeholder string
+}
+
+var (
+	enumsMu sync.RWMutex
+	enums   = map[reflect.Type]*enum{}
+)
+
+// time.Month and time.Weekday are registered as enums so that fields of
+// those types accept either names, e.g. `January` or `Monday`, or numbers
+func init() {
+	var months, weekdays []fmt.Stringer
+	for m := time.January; m <= time.December; m++ {
+		months = append(months, m)
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		weekdays = append(weekdays, d)
+	}
+	_ = RegisterEnum(months...)
+	_ = RegisterEnum(weekdays...)
+	enums[reflect.TypeOf(time.Month(0))].placeholder = "month"
+	enums[reflect.TypeOf(time.Weekday(0))].placeholder = "weekday"
+}
+
+// RegisterEnum registers the values of an enumerated integer type, whose
+// String method returns the name of each value, e.g.
+//
+//	venom.RegisterEnum(Debug, Info, Warn, Error)
+//
+// Fields of the type then accept either the name or the integer value of
+// one of the registered values. All the values must be of the same type.
+func RegisterEnum(values ...fmt.Stringer) error {
+	if len(values) == 0 {
+		return fmt.Errorf("at least one enum value must be specified")
+	}
+
+	typ := reflect.TypeOf(values[0])
+	e := &enum{}
+	for _, value := range values {
+		val := reflect.ValueOf(value)
+		if val.Type() != typ {
+			return fmt.Errorf("enum values must be of the same type, found '%s' and '%s'", typ, val.Type())
+		}
+		switch val.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			e.values = append(e.values, val.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			e.values = append(e.values, int64(val.Uint()))
+		default:
+			return fmt.Errorf("enum type '%s' must be an integer type", typ)
+		}
+		e.names = append(e.names, value.String())
+	}
+
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+	enums[typ] = e
+	return nil
+}
+
+// lookupEnum returns the registered enum of the given type
+func lookupEnum(typ reflect.Type) (*enum, bool) {
+	enumsMu.RLock()
+	defer enumsMu.RUnlock()
+	e, ok := enums[typ]
+	return e, ok
+}
+
+// parse returns the integer value of the enum value with the given name,
+// compared case insensitively, or with the given integer value
+func (e *enum) parse(value string) (int64, error) {
+	for i, name := range e.names {
+		if strings.EqualFold(name, value) {
+			return e.values[i], nil
+		}
+	}
+	if i, err := strconv.ParseInt(value, 0, 64); err == nil {
+		for _, v := range e.values {
+			if v == i {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid value '%s', must be one of '%s' or their integer values",
+		value, strings.Join(e.names, "', '"))
+}
+
+// name returns the name of the given integer value of the enum
+func (e *enum) name(value int64) string {
+	for i, v := range e.values {
+		if v == value {
+			return e.names[i]
+		}
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+// enumValue implements the pflag.Value interface for a registered enum,
+// accepting either the name or integer value of an enum value
+type enumValue struct {
+	enum  *enum
+	value int64
+}
+
+func newEnumValue(e *enum, val int64) *enumValue {
+	return &enumValue{enum: e, value: val}
+}
+
+func (e *enumValue) String() string {
+	return e.enum.name(e.value)
+}
+
+func (e *enumValue) Set(value string) error {
+	parsed, err := e.enum.parse(value)
+	if err != nil {
+		return err
+	}
+	e.value = parsed
+	return nil
+}
+
+// Type returns the names of the enum values, or the enum's placeholder if
+// it has one, which are displayed as the value placeholder in the usage
+func (e *enumValue) Type() string {
+	if e.enum.placeholder != "" {
+		return e.enum.placeholder
+	}
+	return strings.Join(e.enum.names, "|")
+}
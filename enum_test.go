@@ -0,0 +1,151 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type testLevel int
+
+const (
+	testDebug testLevel = iota
+	testInfo
+	testWarn
+)
+
+func (l testLevel) String() string {
+	return [...]string{"debug", "info", "warn"}[l]
+}
+
+func init() {
+	if err := RegisterEnum(testDebug, testInfo, testWarn); err != nil {
+		panic(err)
+	}
+}
+
+func TestEnum(t *testing.T) {
+	type spec struct {
+		Level testLevel `default:"info"`
+	}
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want testLevel
+	}{
+		{name: "default", want: testInfo},
+		{name: "name", args: []string{"--level=warn"}, want: testWarn},
+		{name: "case insensitive", args: []string{"--level=DEBUG"}, want: testDebug},
+		{name: "integer", args: []string{"--level=2"}, want: testWarn},
+		{name: "env", env: "debug", want: testDebug},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.env != "" {
+				os.Setenv("ENUM_LEVEL", test.env)
+				defer os.Unsetenv("ENUM_LEVEL")
+			}
+			var c spec
+			p, err := New(&c, WithDefault, WithPrefix("ENUM"), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if c.Level != test.want {
+				t.Errorf("expected Level to be '%s', got '%s'", test.want, c.Level)
+			}
+		})
+	}
+}
+
+func TestEnumInvalid(t *testing.T) {
+	var c struct {
+		Level testLevel
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.FlagSet().SetOutput(&strings.Builder{})
+	for _, value := range []string{"trace", "7"} {
+		err := p.Parse([]string{"--level=" + value})
+		if err == nil || !strings.Contains(err.Error(), "must be one of 'debug', 'info', 'warn'") {
+			t.Errorf("%s: expected an error listing the names, got '%v'", value, err)
+		}
+	}
+}
+
+type notInteger string
+
+func (n notInteger) String() string { return string(n) }
+
+func TestRegisterEnumErrors(t *testing.T) {
+	if err := RegisterEnum(); err == nil {
+		t.Error("expected an error registering no values")
+	}
+	if err := RegisterEnum(notInteger("a")); err == nil {
+		t.Error("expected an error registering a non integer type")
+	}
+	if err := RegisterEnum(testDebug, notInteger("a")); err == nil {
+		t.Error("expected an error registering values of different types")
+	}
+}
+
+func TestTimeEnums(t *testing.T) {
+	var c struct {
+		Month   time.Month   `default:"March"`
+		Weekday time.Weekday `default:"1"`
+		Renewal time.Month
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--renewal=december"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Month != time.March || c.Weekday != time.Monday || c.Renewal != time.December {
+		t.Errorf("expected March, Monday, and December, got %s, %s, and %s", c.Month, c.Weekday, c.Renewal)
+	}
+
+	usage := p.FlagSet().FlagUsages()
+	for _, want := range []string{"--month month", "--weekday weekday"} {
+		if !strings.Contains(usage, want) {
+			t.Errorf("expected the usage to contain '%s', got:\n%s", want, usage)
+		}
+	}
+
+	p.FlagSet().SetOutput(&strings.Builder{})
+	for _, arg := range []string{"--weekday=7", "--month=0", "--month=Smarch"} {
+		if err := p.Parse([]string{arg}); err == nil {
+			t.Errorf("expected an error for '%s'", arg)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestEnvNames(t *testing.T) {
+	type spec struct {
+		ListenPort string
+		Mode       string `env:"myApp_Mode"`
+		Short      string `e:"X"`
+	}
+	tests := []struct {
+		prefix string
+		env    map[string]string
+	}{
+		{prefix: "svc", env: map[string]string{"ListenPort": "SVC_LISTEN_PORT", "Mode": "SVC_myApp_Mode", "Short": "SVC_X"}},
+		{prefix: "", env: map[string]string{"ListenPort": "LISTEN_PORT", "Mode": "myApp_Mode", "Short": "X"}},
+	}
+	for _, test := range tests {
+		t.Run(test.prefix, func(t *testing.T) {
+			for _, env := range test.env {
+				os.Setenv(env, "from "+env)
+				defer os.Unsetenv(env)
+			}
+			viper.Reset()
+			defer viper.Reset()
+
+			var c spec
+			if err := AddConfiguration(pflag.NewFlagSet("test", pflag.ContinueOnError), &c, test.prefix, DefaultOptions, nil); err != nil {
+				t.Fatal(err)
+			}
+			for key, env := range test.env {
+				if got := viper.GetString(key); got != "from "+env {
+					t.Errorf("expected %s to be read from '%s', got '%s'", key, env, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvOnlyTypes(t *testing.T) {
+	os.Setenv("APP_LEVELS", "1,-2,3")
+	defer os.Unsetenv("APP_LEVELS")
+	os.Setenv("APP_FEATURES", "a=true,b=false")
+	defer os.Unsetenv("APP_FEATURES")
+
+	var c struct {
+		Levels   []int8
+		Features map[string]bool
+		Weights  []int16 `default:"5,15"`
+	}
+	var warnings []string
+	p, err := New(&c, WithDefault, WithPrefix("APP"), WithViper(viper.New()),
+		WithLogger(func(format string, args ...interface{}) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"levels", "features", "weights"} {
+		if p.FlagSet().Lookup(name) != nil {
+			t.Errorf("expected no flag '--%s'", name)
+		}
+	}
+	if want := "field 'Levels': type '[]int8' cannot be bound to a flag, only the environment variable 'APP_LEVELS' is bound"; !strings.Contains(strings.Join(warnings, "\n"), want) {
+		t.Errorf("expected the warning '%s', got %v", want, warnings)
+	}
+
+	if err := p.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(c.Levels) != "[1 -2 3]" {
+		t.Errorf("expected Levels to be [1 -2 3], got %v", c.Levels)
+	}
+	if fmt.Sprint(c.Features) != "map[a:true b:false]" {
+		t.Errorf("expected Features to be map[a:true b:false], got %v", c.Features)
+	}
+	if fmt.Sprint(c.Weights) != "[5 15]" {
+		t.Errorf("expected Weights to be the default [5 15], got %v", c.Weights)
+	}
+}
+
+func TestEnvOnlyTypesWithoutEnv(t *testing.T) {
+	var c struct {
+		Levels []int8
+	}
+	if _, err := New(&c, GenerateFlag, WithViper(viper.New())); err == nil {
+		t.Error("expected an error for a type that cannot be a flag and has no environment variable")
+	}
+}
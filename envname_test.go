@@ -0,0 +1,140 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestSanitizeEnv(t *testing.T) {
+	for name, want := range map[string]string{
+		"MY_APP_PORT":   "MY_APP_PORT",
+		"MY-APP_PORT":   "MY_APP_PORT",
+		"9APP_PORT":     "_9APP_PORT",
+		"APP.V2_PORT":   "APP_V2_PORT",
+		"":              "",
+		"APP_PORT_2024": "APP_PORT_2024",
+	} {
+		if got := sanitizeEnv(name); got != want {
+			t.Errorf("expected '%s' to be sanitized to '%s', got '%s'", name, want, got)
+		}
+		if got := sanitizeEnv(name); got != "" && !isValidEnv(got) {
+			t.Errorf("expected '%s' to be a valid name", got)
+		}
+	}
+}
+
+func TestInvalidEnvName(t *testing.T) {
+	type spec struct {
+		Größe int
+	}
+	_, err := New(&spec{}, WithDefault, WithPrefix("APP"), WithViper(viper.New()))
+	if err == nil || !strings.Contains(err.Error(), "invalid environment variable name 'APP_GRÖßE'") {
+		t.Errorf("expected an invalid name error, got '%v'", err)
+	}
+
+	issues, err := Lint(&spec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messages := lintMessages(issues, "Größe"); len(messages) != 1 || !strings.Contains(messages[0], "not a valid POSIX name") {
+		t.Errorf("expected Lint to report the name, got %v", messages)
+	}
+
+	issues, err = Lint(&struct {
+		Port int `env:"my-port"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messages := lintMessages(issues, "Port"); len(messages) != 0 {
+		t.Errorf("expected an explicit env name to be used as is, got %v", messages)
+	}
+}
+
+func TestWithSanitizedEnv(t *testing.T) {
+	os.Setenv("APP_GR__E", "8080")
+	defer os.Unsetenv("APP_GR__E")
+
+	var c struct {
+		Größe int
+	}
+	p, err := New(&c, WithDefault|WithSanitizedEnv, WithPrefix("APP"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Größe != 8080 {
+		t.Errorf("expected Größe to be read from 'APP_GR__E', got %d", c.Größe)
+	}
+}
+
+func TestInvalidPrefix(t *testing.T) {
+	for _, prefix := range []string{"my-app", "my app", "9app"} {
+		var c struct {
+			Port int
+		}
+		_, err := New(&c, WithDefault, WithPrefix(prefix), WithViper(viper.New()))
+		if err == nil || !strings.Contains(err.Error(), "invalid prefix '"+prefix+"'") {
+			t.Errorf("expected an error naming the prefix '%s', got '%v'", prefix, err)
+		}
+	}
+
+	// The prefix is valid with its trailing separator removed, and is not
+	// used when no field has an environment variable
+	var c struct {
+		Port int
+	}
+	if _, err := New(&c, WithDefault, WithPrefix("app_"), WithViper(viper.New())); err != nil {
+		t.Errorf("expected a trailing separator to be ignored, got '%v'", err)
+	}
+	if _, err := New(&c, GenerateFlag, WithPrefix("my-app"), WithViper(viper.New())); err != nil {
+		t.Errorf("expected the prefix not to be checked without environment variables, got '%v'", err)
+	}
+}
+
+func TestSanitizedPrefix(t *testing.T) {
+	os.Setenv("MY_APP_PORT", "8080")
+	defer os.Unsetenv("MY_APP_PORT")
+	os.Setenv("MY_APP_HOST", "example.com")
+	defer os.Unsetenv("MY_APP_HOST")
+
+	var c struct {
+		Port int
+		Host string `env:"HOST"`
+	}
+	p, err := New(&c, WithDefault|WithSanitizedEnv, WithPrefix("my-app"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Port != 8080 || c.Host != "example.com" {
+		t.Errorf("expected the values of 'MY_APP_PORT' and 'MY_APP_HOST', got %d and '%s'", c.Port, c.Host)
+	}
+}
@@ -0,0 +1,94 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ConfigError describes a problem with a field of a configuration
+// specification, or with its value, found when processing or validating
+// the specification. It can be extracted from the errors returned, e.g. by
+// AddConfiguration or Apply, using errors.As and is rendered as JSON, e.g.
+// `{"field":"Server.Port","tag":"max","reason":"..."}`, so that it can be
+// reported by tooling or a JSON API.
+type ConfigError struct {
+	// Field the path of the field, e.g. Server.Port
+	Field string
+	// Tag the structure tag to which the problem relates, if any
+	Tag string
+	// Reason describes the problem
+	Reason string
+
+	err error
+}
+
+// configError returns a ConfigError for the field, relating to the given
+// tag, that wraps the error
+func configError(f *field, tag string, err error) error {
+	return &ConfigError{Field: f.name, Tag: tag, Reason: err.Error(), err: err}
+}
+
+// configErrorf returns a ConfigError for the field, relating to the given
+// tag, with the reason formatted as per fmt.Errorf
+func configErrorf(f *field, tag string, format string, args ...interface{}) error {
+	return configError(f, tag, fmt.Errorf(format, args...))
+}
+
+func (e *ConfigError) Error() string {
+	if e.Field == "" {
+		return e.Reason
+	}
+	return fmt.Sprintf("field '%s': %s", e.Field, e.Reason)
+}
+
+// Unwrap returns the error that caused the problem, if any
+func (e *ConfigError) Unwrap() error {
+	return e.err
+}
+
+// MarshalJSON renders the error as a JSON object with the members field,
+// tag, and reason, omitting the field and tag when empty
+func (e *ConfigError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Field  string `json:"field,omitempty"`
+		Tag    string `json:"tag,omitempty"`
+		Reason string `json:"reason"`
+	}{e.Field, e.Tag, e.Reason})
+}
+
+// MarshalJSON renders the errors as a JSON list in which each ConfigError
+// is rendered as an object and any other error as an object with only a
+// reason, e.g. a conflict between the fields of an exclusive group
+func (e Errors) MarshalJSON() ([]byte, error) {
+	list := make([]json.Marshaler, len(e))
+	for i, err := range e {
+		list[i] = asConfigError(err)
+	}
+	return json.Marshal(list)
+}
+
+// asConfigError returns the ConfigError wrapped by the error, or a
+// ConfigError with only the error's message as the reason
+func asConfigError(err error) *ConfigError {
+	var configErr *ConfigError
+	if errors.As(err, &configErr) {
+		return configErr
+	}
+	return &ConfigError{Reason: err.Error(), err: err}
+}
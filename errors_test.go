@@ -0,0 +1,95 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestConfigError(t *testing.T) {
+	cause := errors.New("out of range")
+	err := &ConfigError{Field: "Server.Port", Tag: "max", Reason: cause.Error(), err: cause}
+	if want := "field 'Server.Port': out of range"; err.Error() != want {
+		t.Errorf("expected the message '%s', got '%s'", want, err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("expected the error to wrap its cause")
+	}
+
+	for _, test := range []struct {
+		err  *ConfigError
+		want string
+	}{
+		{err, `{"field":"Server.Port","tag":"max","reason":"out of range"}`},
+		{&ConfigError{Field: "Host", Reason: "required"}, `{"field":"Host","reason":"required"}`},
+		{&ConfigError{Reason: "conflict"}, `{"reason":"conflict"}`},
+	} {
+		data, err := json.Marshal(test.err)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.want {
+			t.Errorf("expected the JSON '%s', got '%s'", test.want, data)
+		}
+	}
+}
+
+func TestErrorsJSON(t *testing.T) {
+	var c struct {
+		Port  int    `max:"1024"`
+		Level string `choices:"debug,info"`
+	}
+	err := applyArgs(t, &c, "--port=8080", "--level=trace")
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expected two errors, got '%v'", err)
+	}
+	if want := "field 'Port': value '8080' is greater than the maximum '1024'"; errs[0].Error() != want {
+		t.Errorf("expected the message '%s', got '%s'", want, errs[0].Error())
+	}
+	var cerr *ConfigError
+	if !errors.As(errs[1], &cerr) || cerr.Field != "Level" || cerr.Tag != "choices" {
+		t.Errorf("expected a ConfigError for the choices tag of Level, got %#v", errs[1])
+	}
+
+	data, err := json.Marshal(append(errs, errors.New("fields 'A' and 'B' conflict")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rendered []map[string]string
+	if err := json.Unmarshal(data, &rendered); err != nil {
+		t.Fatalf("expected a JSON list, got '%s': %s", data, err)
+	}
+	want := []map[string]string{
+		{"field": "Port", "tag": "max", "reason": "value '8080' is greater than the maximum '1024'"},
+		{"field": "Level", "tag": "choices", "reason": "value 'trace' is not one of 'debug', 'info'"},
+		{"reason": "fields 'A' and 'B' conflict"},
+	}
+	if len(rendered) != len(want) {
+		t.Fatalf("expected %d errors, got '%s'", len(want), data)
+	}
+	for i := range want {
+		for key, value := range want[i] {
+			if rendered[i][key] != value {
+				t.Errorf("expected '%s' of error %d to be '%s', got '%s'", key, i, value, rendered[i][key])
+			}
+		}
+		if len(rendered[i]) != len(want[i]) {
+			t.Errorf("expected error %d to be %v, got %v", i, want[i], rendered[i])
+		}
+	}
+}
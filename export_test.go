@@ -0,0 +1,99 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type exportSpec struct {
+	Zone     string
+	Timeout  time.Duration
+	Tags     []string
+	Password string
+	Backup   *string
+	DB       struct {
+		Host string
+	}
+}
+
+func TestExportResolved(t *testing.T) {
+	c := exportSpec{Zone: "eu", Timeout: 90 * time.Second, Tags: []string{"a,b", "c"}, Password: "hunter2"}
+	c.DB.Host = "db.local"
+
+	env, err := ExportResolved(&c, "APP", DefaultOptions, "Password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"APP_ZONE=eu",
+		"APP_TIMEOUT=1m30s",
+		`APP_TAGS="a,b",c`,
+		"APP_PASSWORD=****",
+		"APP_DB_HOST=db.local",
+	}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("expected the assignments:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(env, "\n"))
+	}
+}
+
+func TestExportResolvedRoundTrip(t *testing.T) {
+	c := exportSpec{Zone: "us", Timeout: time.Minute, Tags: []string{"x,y", "z"}}
+	c.DB.Host = "remote"
+	env, err := ExportResolved(&c, "EXP", DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, assignment := range env {
+		parts := strings.SplitN(assignment, "=", 2)
+		os.Setenv(parts[0], parts[1])
+		defer os.Unsetenv(parts[0])
+	}
+
+	var read exportSpec
+	p, err := New(&read, WithDefault, WithPrefix("EXP"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if read.Zone != c.Zone || read.Timeout != c.Timeout || !reflect.DeepEqual(read.Tags, c.Tags) || read.DB.Host != c.DB.Host {
+		t.Errorf("expected the exported values to be read back as %+v, got %+v", c, read)
+	}
+}
+
+func TestExportResolvedSorted(t *testing.T) {
+	c := exportSpec{Zone: "eu", Tags: []string{"a"}}
+	c.DB.Host = "db"
+	options := DefaultOptions
+	options.Flags |= WithSortedOutput
+
+	env, err := ExportResolved(&c, "APP", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"APP_DB_HOST=db", "APP_PASSWORD=", "APP_TAGS=a", "APP_TIMEOUT=0s", "APP_ZONE=eu"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("expected the sorted assignments %v, got %v", want, env)
+	}
+}
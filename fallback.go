@@ -0,0 +1,65 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+// Fallback specifies how a field whose default cannot be parsed as the
+// field's type is processed
+type Fallback int
+
+// Defines how a field whose default cannot be parsed is processed
+const (
+	// FallbackError a default that cannot be parsed is an error, which aborts
+	// processing
+	FallbackError Fallback = iota
+
+	// FallbackZero the default is replaced by the zero value of the field's
+	// type and a warning is logged
+	FallbackZero
+
+	// FallbackSkip the field is ignored, as if tagged `ignored`, and a
+	// warning is logged
+	FallbackSkip
+)
+
+// WithDefaultParseFallback specifies how a field whose default cannot be
+// parsed is processed, e.g. so that tooling can process a partially broken
+// specification. By default this is an error.
+func WithDefaultParseFallback(fallback Fallback) Option {
+	return optionFunc(func(p *Processor) {
+		p.options.DefaultParseFallback = fallback
+	})
+}
+
+// applyDefaultFallback applies the configured fallback to a field whose
+// default cannot be parsed, leaving the field unchanged when the fallback
+// is FallbackError so that the error is reported when it is bound
+func (f *field) applyDefaultFallback(options ProcessingOptions) {
+	if options.DefaultParseFallback == FallbackError || f.def == "" {
+		return
+	}
+	_, err := f.defaultValue()
+	if err == nil {
+		return
+	}
+	switch options.DefaultParseFallback {
+	case FallbackZero:
+		options.logf("field '%s': %s, using the zero value", f.name, err)
+		f.def = ""
+	case FallbackSkip:
+		options.logf("field '%s': %s, skipping the field", f.name, err)
+		f.ignored = true
+	}
+}
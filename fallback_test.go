@@ -0,0 +1,100 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+type fallbackSpec struct {
+	Host string `default:"localhost"`
+	Port int    `default:"http"`
+}
+
+func TestDefaultParseFallback(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		fallback Fallback
+		err      string
+		flag     bool
+		warning  string
+	}{
+		{"error", FallbackError, "cannot parse default 'http' as int", true, ""},
+		{"zero", FallbackZero, "", true, "using the zero value"},
+		{"skip", FallbackSkip, "", false, "skipping the field"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var warnings []string
+			options := DefaultOptions
+			options.DefaultParseFallback = test.fallback
+			options.Logger = func(format string, args ...interface{}) {
+				warnings = append(warnings, fmt.Sprintf(format, args...))
+			}
+
+			var c fallbackSpec
+			v := viper.New()
+			flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			err := AddConfigurationTo(v, flagSet, &c, "", options, nil)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("expected the error '%s', got '%v'", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := flagSet.Lookup("port") != nil; got != test.flag {
+				t.Errorf("expected '--port' defined to be %t, got %t", test.flag, got)
+			}
+			if flagSet.Lookup("host") == nil {
+				t.Error("expected the flag '--host' to be defined")
+			}
+			all := strings.Join(warnings, "\n")
+			if !strings.Contains(all, "field 'Port': cannot parse default 'http' as int") || !strings.Contains(all, test.warning) {
+				t.Errorf("expected a warning '%s', got:\n%s", test.warning, all)
+			}
+
+			if err := PopulateFrom(v, &c, "", options); err != nil {
+				t.Fatal(err)
+			}
+			if c.Port != 0 || c.Host != "localhost" {
+				t.Errorf("expected Port to be 0 and Host 'localhost', got %d and '%s'", c.Port, c.Host)
+			}
+		})
+	}
+}
+
+func TestWithDefaultParseFallback(t *testing.T) {
+	var c fallbackSpec
+	p, err := New(&c, WithDefault, WithViper(viper.New()), WithDefaultParseFallback(FallbackZero))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--port=8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Port != 8080 {
+		t.Errorf("expected Port to be 8080, got %d", c.Port)
+	}
+}
@@ -0,0 +1,1093 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+)
+
+// field describes the configuration derived from a single member of a
+// configuration specification
+type field struct {
+	name  string
+	index []int
+	typ   reflect.Type
+	tag   reflect.StructTag
+	key   string
+	read  string
+	env   string
+	long  string
+	short string
+	def   string
+	help  string
+
+	// extendedDurations is true if durations may use the 'd' and 'w' units
+	extendedDurations bool
+
+	// lenientNumbers is true if numbers are parsed by parseLenientNumber
+	lenientNumbers bool
+
+	// ignored is true if the field is not processed, because it is tagged
+	// `ignored` or is untagged when OnlyTagged is set
+	ignored bool
+
+	// pointer is true if the struct field is a pointer to a value of the
+	// field's type, which is nil unless a value was set
+	pointer bool
+
+	// aliasEnvs and aliasLongs the environment variables and long flags
+	// derived from the former names of the field, see describeAliases
+	aliasEnvs  []string
+	aliasLongs []string
+
+	// implementation the implementation, selected using the `impl` tag,
+	// of the interface field within which the field was described, if any
+	implementation *implementation
+}
+
+// value returns the value of the field within the given struct value,
+// dereferencing a pointer field, and false if the field is a nil pointer or
+// is within an implementation that has not been installed
+func (f *field) value(specElem reflect.Value) (reflect.Value, bool) {
+	if f.implementation != nil && !f.implementation.installed(specElem) {
+		return reflect.Value{}, false
+	}
+	value := fieldByIndex(specElem, f.index)
+	if !f.pointer {
+		return value, true
+	}
+	if value.IsNil() {
+		return reflect.Value{}, false
+	}
+	return value.Elem(), true
+}
+
+// path returns the names of the struct fields leading to, and including,
+// the field
+func (f *field) path() []string {
+	return strings.Split(f.name, ".")
+}
+
+// tagValue returns the value of the first of the given tags that is set
+// to a non-empty value
+func tagValue(tag reflect.StructTag, names ...string) string {
+	for _, name := range names {
+		if value := tag.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parent describes the struct field, if any, enclosing the fields being
+// described. Each name is the prefix applied to the corresponding name of
+// the enclosed fields.
+type parent struct {
+	index []int
+	name  string
+	key   string
+	env   string
+	long  string
+
+	// implementation is set if the fields are within an implementation
+	// selected using the `impl` tag, within which no further
+	// implementation can be selected
+	implementation *implementation
+
+	// selected is true if the fields are within a struct selected by the
+	// tag selector, so that all of them are processed
+	selected bool
+}
+
+// nestedKey returns the key prefix of the fields of a nested struct, which
+// is the parent's key prefix, unchanged, when flat keys were requested
+func nestedKey(prefix, name string, options ProcessingOptions) string {
+	if options.Flags&WithFlatKeys != 0 {
+		return prefix
+	}
+	return join(prefix, options.keyDelimiter(), name)
+}
+
+// join joins the parent prefix and the name with the separator, returning
+// the name unchanged when there is no parent prefix
+func join(prefix, sep, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + sep + name
+}
+
+// describeFields walks the configSpecification and returns a description
+// of each field that should be processed. No flags or viper bindings are
+// created.
+func describeFields(configSpecification interface{}, prefix string, options ProcessingOptions) ([]*field, error) {
+	all, err := describeAllFields(configSpecification, prefix, options)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := all[:0]
+	for _, f := range all {
+		if !f.ignored {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// describeAllFields walks the configSpecification and returns a description
+// of each field, including those that are ignored
+func describeAllFields(configSpecification interface{}, prefix string, options ProcessingOptions) ([]*field, error) {
+	spec := reflect.ValueOf(configSpecification)
+
+	if spec.Kind() != reflect.Ptr || spec.Elem().Kind() != reflect.Struct {
+		return nil, ErrSpecificationType
+	}
+
+	// The prefix is also applied to the names specified by env tags, so it
+	// is sanitized along with the generated names
+	if options.Flags&WithSanitizedEnv != 0 {
+		prefix = sanitizeEnv(strings.ToUpper(prefix))
+	}
+	return describeStruct(spec.Elem(), &parent{key: options.KeyNamespace}, prefix, options)
+}
+
+// describeStruct describes the fields of the given struct value, recursing
+// into fields that are themselves structs. The names of the fields within a
+// nested struct are prefixed by the name of the struct field, e.g. the
+// `Host` field of a `Server` field has the key `Server.Host`, the
+// environment variable `SERVER_HOST`, and the flag `--server-host`. The
+// fields of a new instance of the implementation selected by the `impl` tag
+// of an interface field are described in the same way, without modifying
+// the interface field.
+func describeStruct(specElem reflect.Value, p *parent, prefix string, options ProcessingOptions) ([]*field, error) {
+	specType := specElem.Type()
+
+	var fields []*field
+	for i := 0; i < specType.NumField(); i++ {
+		fieldType := specType.Field(i)
+
+		index := append(append([]int{}, p.index...), i)
+		ignored := &field{
+			name:    join(p.name, ".", fieldType.Name),
+			index:   index,
+			typ:     fieldType.Type,
+			tag:     fieldType.Tag,
+			ignored: true,
+
+			implementation: p.implementation,
+		}
+
+		// A member for other profiles is not processed, as if ignored
+		if isTrue(fieldType.Tag.Get("ignored")) || !options.inProfile(fieldType.Tag) {
+			fields = append(fields, ignored)
+			continue
+		}
+		selected := p.selected || options.selects(fieldType.Tag)
+
+		// The name of a nested struct, used as the prefix of the names of
+		// its fields, can be specified using the `name` or `prefix` tag
+		segment := fieldType.Name
+		if name := tagValue(fieldType.Tag, "name", "prefix"); name != "" && (isNestedStruct(fieldType.Type) || isImplementation(fieldType)) {
+			segment = name
+		}
+		envName := strings.ToUpper(splitIntoWords(segment, options.EnvSeparator))
+		longName := strings.ToLower(splitIntoWords(segment, options.LongSeparator))
+
+		// The fields of an embedded struct are promoted, i.e. processed as
+		// if they were fields of the enclosing struct, unless it has a
+		// `name` tag. The embedded struct itself may be unexported, e.g.
+		// when embedding a struct from another package, but only its
+		// settable fields are processed.
+		if fieldType.Anonymous && isNestedStruct(fieldType.Type) {
+			nested := &parent{
+				index:          index,
+				name:           p.name,
+				key:            p.key,
+				env:            p.env,
+				long:           p.long,
+				implementation: p.implementation,
+				selected:       selected,
+			}
+			if segment != fieldType.Name {
+				nested = &parent{
+					index:          index,
+					name:           join(p.name, ".", fieldType.Name),
+					key:            nestedKey(p.key, segment, options),
+					env:            join(p.env, options.EnvSeparator, envName),
+					long:           join(p.long, options.LongSeparator, longName),
+					implementation: p.implementation,
+					selected:       selected,
+				}
+			}
+			described, err := describeStruct(specElem.Field(i), nested, prefix, options)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, described...)
+			continue
+		}
+
+		// If the field should not be processed, implicitly, then skip
+		if !specElem.Field(i).CanSet() {
+			continue
+		}
+
+		// A provider is not bound, but called by Apply to compute the
+		// value of its companion field
+		if isProvider(fieldType) {
+			fields = append(fields, ignored)
+			continue
+		}
+
+		nested := &parent{
+			index:          index,
+			name:           join(p.name, ".", fieldType.Name),
+			key:            nestedKey(p.key, segment, options),
+			env:            join(p.env, options.EnvSeparator, envName),
+			long:           join(p.long, options.LongSeparator, longName),
+			implementation: p.implementation,
+			selected:       selected,
+		}
+		if isNestedStruct(fieldType.Type) && !isTrue(fieldType.Tag.Get("raw")) {
+			described, err := describeStruct(specElem.Field(i), nested, prefix, options)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, described...)
+			continue
+		}
+
+		// Only a single level of implementation is selected, i.e. an
+		// implementation cannot itself select an implementation
+		if isImplementation(fieldType) {
+			if p.implementation != nil {
+				return nil, configErrorf(ignored, "impl", "impl cannot be used within an implementation")
+			}
+			impl, instance, err := selectImplementation(ignored)
+			if err != nil {
+				return nil, err
+			}
+			nested.implementation = impl
+			described, err := describeStruct(instance, nested, prefix, options)
+			if err != nil {
+				return nil, err
+			}
+
+			// An implementation without configuration is still recorded,
+			// as an ignored field, so that it is installed by Apply
+			if len(described) == 0 {
+				ignored.implementation = impl
+				described = append(described, ignored)
+			}
+			fields = append(fields, described...)
+			continue
+		}
+
+		// When requested, fields without any configuration tags, or not
+		// selected by the tag selector, are skipped
+		if options.Flags&OnlyTagged != 0 && !hasConfigurationTag(fieldType.Tag) || options.TagSelectorKey != "" && !selected {
+			fields = append(fields, ignored)
+			continue
+		}
+
+		// A pointer to a supported type is processed as the type pointed
+		// to, so that the pointer can be left nil when no value is set
+		typ := fieldType.Type
+		pointer := typ.Kind() == reflect.Ptr && isSupportedType(typ.Elem())
+		if pointer {
+			typ = typ.Elem()
+		}
+
+		f := &field{
+			name:  join(p.name, ".", fieldType.Name),
+			index: index,
+			typ:   typ,
+			tag:   fieldType.Tag,
+			key:   tagValue(fieldType.Tag, "key"),
+			read:  tagValue(fieldType.Tag, "readKey"),
+			short: tagValue(fieldType.Tag, "short", "s"),
+			def:   osDefault(fieldType.Tag),
+			help:  tagValue(fieldType.Tag, "help", "h"),
+
+			extendedDurations: options.Flags&WithExtendedDurations != 0,
+			lenientNumbers:    options.Flags&WithLenientNumbers != 0,
+			pointer:           pointer,
+			implementation:    p.implementation,
+		}
+
+		// A default provided programmatically, e.g. from a constant,
+		// overrides the default tag
+		if options.DefaultsFunc != nil {
+			if def, ok := options.DefaultsFunc(f.path()); ok {
+				f.def = def
+			}
+		}
+
+		if f.expandsHome(options) {
+			f.def = expandHome(f.def)
+		}
+
+		// Explicitly specified keys are relative to the namespace, if any,
+		// which is otherwise the key of the outermost parent
+		if f.key == "" {
+			f.key = join(p.key, options.keyDelimiter(), fieldType.Name)
+		} else {
+			f.key = join(options.KeyNamespace, options.keyDelimiter(), f.key)
+		}
+		if f.read == "" {
+			f.read = f.key
+		} else {
+			f.read = join(options.KeyNamespace, options.keyDelimiter(), f.read)
+		}
+
+		// Viper lower cases keys, so unless requested otherwise the keys
+		// are lower cased so that those reported, e.g. by DumpConfig,
+		// match those used by viper
+		if options.Flags&WithCaseSensitiveKeys == 0 {
+			f.key = strings.ToLower(f.key)
+			f.read = strings.ToLower(f.read)
+		}
+
+		// A replacer, if any, is applied to the final keys, e.g. to match a
+		// key replacer configured on the viper instance
+		if options.KeyReplacer != nil {
+			f.key = options.KeyReplacer.Replace(f.key)
+			f.read = options.KeyReplacer.Replace(f.read)
+		}
+
+		// If an option for an environment variable configuration was set then process
+		f.env = prefixedEnv(tagValue(fieldType.Tag, "env", "e"), prefix, options.EnvSeparator, true)
+		if f.env == "" && options.Flags&GenerateEnv != 0 {
+			f.env = prefixedEnv(join(p.env, options.EnvSeparator, envName), prefix, options.EnvSeparator, false)
+			if options.Flags&WithSanitizedEnv != 0 {
+				f.env = sanitizeEnv(f.env)
+			}
+		}
+
+		f.long = tagValue(fieldType.Tag, "long", "l")
+		if f.long == "" && options.Flags&GenerateFlag != 0 {
+			f.long = join(p.long, options.LongSeparator, longName)
+		}
+		f.describeAliases(p, prefix, options)
+		f.applyDefaultFallback(options)
+
+		options.trace(f, TraceNaming, nil)
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// prefixedEnv returns the name of an environment variable prefixed by the
+// upper cased prefix, if any. Only the prefix is upper cased so that an
+// explicitly specified name is used verbatim, and an explicitly specified
+// name that already starts with the prefix, compared case insensitively, is
+// not prefixed again.
+func prefixedEnv(name, prefix, sep string, explicit bool) string {
+	prefix = strings.TrimSuffix(strings.ToUpper(prefix), sep)
+	if name == "" || prefix == "" {
+		return name
+	}
+	if explicit && strings.HasPrefix(strings.ToUpper(name), prefix+sep) {
+		return name
+	}
+	return prefix + sep + name
+}
+
+var envRegexp = regexp.MustCompile("^[A-Z_][A-Z0-9_]*$")
+var nonEnvRegexp = regexp.MustCompile("[^A-Z0-9_]")
+
+// isValidEnv returns true if the name is a valid POSIX environment variable
+// name, i.e. consists of upper case letters, digits, and '_' and does not
+// start with a digit
+func isValidEnv(name string) bool {
+	return envRegexp.MatchString(name)
+}
+
+// sanitizeEnv returns a valid POSIX environment variable name derived from
+// the name by replacing each invalid character with '_' and prefixing a
+// leading digit with '_'
+func sanitizeEnv(name string) string {
+	name = nonEnvRegexp.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// isNestedStruct returns true if the type is a struct whose fields should
+// be described, rather than a struct, such as time.Time, that is treated
+// as a single value
+func isNestedStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && !isSupportedType(typ)
+}
+
+// isRaw returns true if the field is bound as a string, regardless of its
+// type, and the resolved string is not converted to the field's type
+func (f *field) isRaw() bool {
+	return isTrue(f.tag.Get("raw"))
+}
+
+// supported returns true if the field can be bound to a flag, i.e. it is
+// of a supported type or is a raw field
+func (f *field) supported() bool {
+	return f.isRaw() || isSupportedType(f.typ)
+}
+
+// parseable returns true if values of the field can be parsed from a
+// string, although the field cannot necessarily be bound to a flag
+func (f *field) parseable() bool {
+	return f.supported() || isParseableType(f.typ)
+}
+
+// isParseableType returns true if values of the given type can be parsed
+// from a string, including lists and maps, such as []int8 or
+// map[string]bool, for which pflag has no flag type
+func isParseableType(typ reflect.Type) bool {
+	if isSupportedType(typ) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Slice:
+		return isScalarType(typ.Elem())
+	case reflect.Map:
+		return typ.Key().Kind() == reflect.String && isScalarType(typ.Elem())
+	}
+	return false
+}
+
+// isScalarType returns true if the type is supported and parsed as a
+// single value, rather than as a list or map, e.g. net.IP or []byte
+func isScalarType(typ reflect.Type) bool {
+	if !isSupportedType(typ) {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Slice:
+		return typ == ipType || typ == ipMaskType || isTextType(typ) || isBytesType(typ)
+	case reflect.Map:
+		return isTextType(typ)
+	}
+	return true
+}
+
+// isSupportedType returns true if values of the given type can be parsed
+// from a string and bound to a flag
+func isSupportedType(typ reflect.Type) bool {
+	switch typ {
+	case durationType, ipType, ipMaskType, urlType, timeType:
+		return true
+	}
+	if isTextType(typ) {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Slice:
+		if isBytesType(typ) {
+			return true
+		}
+		// The element type is inspected, rather than the slice type, so
+		// that named slice types, e.g. `type Schedule []time.Duration`,
+		// are supported
+		return isSupportedElem(typ.Elem())
+	case reflect.Map:
+		return isSupportedMap(typ)
+	}
+	return false
+}
+
+// isSupportedElem returns true if slices of the given element type can be
+// bound to one of pflag's slice flag types
+func isSupportedElem(typ reflect.Type) bool {
+	if typ == durationType {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isSupportedMap returns true if the type is a map of strings to strings,
+// ints, or lists of strings, which can be bound to a flag
+func isSupportedMap(typ reflect.Type) bool {
+	if typ.Key().Kind() != reflect.String {
+		return false
+	}
+	switch typ.Elem().Kind() {
+	case reflect.String, reflect.Int:
+		return true
+	}
+	return isStringSliceMap(typ)
+}
+
+// isStringSliceMap returns true if the type is a map of strings to lists of
+// strings, such as map[string][]string for multi-valued HTTP headers, which
+// is bound to a flag that can be repeated
+func isStringSliceMap(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String &&
+		typ.Elem().Kind() == reflect.Slice && typ.Elem().Elem().Kind() == reflect.String
+}
+
+// mapSeparators returns the separators between the entries of a map value
+// and between the key and value of each entry, as specified by the
+// `pairSeparator` and `kvSeparator` tags. The entries are separated by ','
+// by default or, as the values of a map of lists are comma separated, by
+// ';'. Keys and values are separated by '=' by default.
+func (f *field) mapSeparators() (string, string) {
+	pairSep := f.tag.Get("pairSeparator")
+	if pairSep == "" {
+		pairSep = ","
+		if isStringSliceMap(f.typ) {
+			pairSep = ";"
+		}
+	}
+	kvSep := f.tag.Get("kvSeparator")
+	if kvSep == "" {
+		kvSep = "="
+	}
+	return pairSep, kvSep
+}
+
+// parseMap parses a map value of the form `a=1,b=2`, optionally enclosed
+// in brackets as rendered by pflag for map flags, or `a=1,2;b=3` for a map
+// of lists. Each entry is split at the first key value separator, so a
+// value may contain it, and the value is parsed as per the map's value
+// type. The values of entries with the same key in a map of lists are
+// combined.
+func (f *field) parseMap(value string) (interface{}, error) {
+	pairSep, kvSep := f.mapSeparators()
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		value = value[1 : len(value)-1]
+	}
+
+	typ := reflect.MapOf(basicType(f.typ.Key()), basicType(f.typ.Elem()))
+	m := reflect.MakeMap(typ)
+	if value == "" {
+		return m.Interface(), nil
+	}
+	elem := f.elem()
+	for _, pair := range strings.Split(value, pairSep) {
+		kv := strings.SplitN(pair, kvSep, 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid map entry '%s', must be of the form 'key%svalue'", pair, kvSep)
+		}
+		key := reflect.ValueOf(kv[0])
+		parsed, err := elem.parse(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid map entry '%s': %w", pair, err)
+		}
+		val := reflect.ValueOf(parsed)
+		if list := m.MapIndex(key); list.IsValid() && typ.Elem().Kind() == reflect.Slice {
+			val = reflect.AppendSlice(list, val)
+		}
+		m.SetMapIndex(key, val)
+	}
+	return m.Interface(), nil
+}
+
+// elem returns a field describing the elements of a slice field or the
+// values of a map field
+func (f *field) elem() *field {
+	elem := *f
+	elem.typ = f.typ.Elem()
+	return &elem
+}
+
+// splitList splits a list value, optionally enclosed in brackets as
+// rendered by pflag for slice flags, into its comma separated elements.
+// As with CSV, an element may be enclosed in double quotes so that it can
+// contain commas, e.g. `"a,b",c`, and a double quote within a quoted
+// element is escaped by doubling it. Whitespace around each element, e.g.
+// `1s, 5s, 30s`, is removed, although not within the quotes of a quoted
+// element.
+func splitList(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	if value == "" {
+		return nil
+	}
+
+	var parts []string
+	var b strings.Builder
+
+	// keep is the length of the element up to the end of its last quoted
+	// section, which is not trimmed
+	quoted, keep := false, 0
+	element := func() string {
+		s := b.String()
+		return s[:keep] + strings.TrimRightFunc(s[keep:], unicode.IsSpace)
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '"' && quoted && i+1 < len(value) && value[i+1] == '"':
+			b.WriteByte('"')
+			i++
+		case c == '"':
+			quoted = !quoted
+			keep = b.Len()
+		case c == ',' && !quoted:
+			parts = append(parts, element())
+			b.Reset()
+			keep = 0
+		case !quoted && b.Len() == 0 && unicode.IsSpace(rune(c)):
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return append(parts, element())
+}
+
+// trimsList returns true if the field is bound to a slice flag whose pflag
+// value does not remove the whitespace around the elements of a list,
+// rather than a flag whose value is parsed as a single value, e.g. a
+// []byte, or one that already does so
+func (f *field) trimsList() bool {
+	if f.typ.Kind() != reflect.Slice || f.isRaw() || f.isArray() || isScalarType(f.typ) {
+		return false
+	}
+	return basicType(f.typ.Elem()) != durationType
+}
+
+// quoteListElement quotes an element of a list, as understood by
+// splitList, if it contains a comma or double quote
+func quoteListElement(value string) string {
+	if !strings.ContainsAny(value, ",\"") {
+		return value
+	}
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
+
+// layouts returns the layouts used to parse a time.Time field
+func (f *field) layouts() []string {
+	return timeLayouts(f.tag.Get("layout"))
+}
+
+// defaultValue returns the parsed default value for the field or, when no
+// default is specified, the zero value. An error parsing the default names
+// the default and the field's type, unless the field is a secret.
+func (f *field) defaultValue() (interface{}, error) {
+	if f.isRaw() {
+		return f.def, nil
+	}
+	if f.def != "" {
+		value, err := f.parse(f.def)
+		if err != nil && f.isSecret() {
+			return nil, fmt.Errorf("cannot parse default as %s", f.typ)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse default '%s' as %s: %w", f.def, f.typ, err)
+		}
+		return value, nil
+	}
+
+	return reflect.Zero(basicType(f.typ)).Interface(), nil
+}
+
+// basicType returns the unnamed type with the same kind as the given type,
+// e.g. int for a type declared as `type Level int` or []time.Duration for a
+// type declared as `type Schedule []time.Duration`
+func basicType(typ reflect.Type) reflect.Type {
+	switch typ {
+	case durationType, ipType, ipMaskType, urlType, timeType:
+		return typ
+	}
+	if isTextType(typ) {
+		return typ
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice:
+		return reflect.SliceOf(basicType(typ.Elem()))
+	case reflect.Map:
+		return reflect.MapOf(basicType(typ.Key()), basicType(typ.Elem()))
+	case reflect.String:
+		return reflect.TypeOf("")
+	case reflect.Bool:
+		return reflect.TypeOf(false)
+	case reflect.Int:
+		return reflect.TypeOf(int(0))
+	case reflect.Int8:
+		return reflect.TypeOf(int8(0))
+	case reflect.Int16:
+		return reflect.TypeOf(int16(0))
+	case reflect.Int32:
+		return reflect.TypeOf(int32(0))
+	case reflect.Int64:
+		return reflect.TypeOf(int64(0))
+	case reflect.Uint:
+		return reflect.TypeOf(uint(0))
+	case reflect.Uint8:
+		return reflect.TypeOf(uint8(0))
+	case reflect.Uint16:
+		return reflect.TypeOf(uint16(0))
+	case reflect.Uint32:
+		return reflect.TypeOf(uint32(0))
+	case reflect.Uint64:
+		return reflect.TypeOf(uint64(0))
+	case reflect.Float32:
+		return reflect.TypeOf(float32(0))
+	case reflect.Float64:
+		return reflect.TypeOf(float64(0))
+	case reflect.Complex64:
+		return reflect.TypeOf(complex64(0))
+	case reflect.Complex128:
+		return reflect.TypeOf(complex128(0))
+	}
+	return typ
+}
+
+// durationUnit returns the unit of a bare number specified as the value of
+// a duration field, as specified by the `unit` tag, e.g. `unit:"s"` so that
+// `30` is 30 seconds, and false if no unit was specified
+func (f *field) durationUnit() (time.Duration, bool, error) {
+	tag := f.tag.Get("unit")
+	if tag == "" {
+		return 0, false, nil
+	}
+	unit, ok := durationUnits[tag]
+	if !ok {
+		return 0, false, fmt.Errorf("invalid unit '%s', must be one of 'ns', 'us', 'ms', 's', 'm', or 'h'", tag)
+	}
+	return unit, true, nil
+}
+
+// checkUnit returns an error if the field's `unit` tag is invalid or is
+// specified for a field that is not a duration or list of durations
+func (f *field) checkUnit() error {
+	if f.tag.Get("unit") == "" {
+		return nil
+	}
+	if f.typ != durationType && (f.typ.Kind() != reflect.Slice || f.typ.Elem() != durationType) {
+		return fmt.Errorf("unit is only valid for duration fields")
+	}
+	_, _, err := f.durationUnit()
+	return err
+}
+
+// unitsHint returns a hint listing the units accepted by a duration field,
+// or a list of durations, e.g. `(units: ns,us,ms,s,m,h)`, including the
+// unit of a bare number if the field has a `unit` tag, or "" for any other
+// field
+func (f *field) unitsHint() string {
+	if f.isRaw() || f.typ != durationType && (f.typ.Kind() != reflect.Slice || f.typ.Elem() != durationType) {
+		return ""
+	}
+	units := "ns,us,ms,s,m,h"
+	if f.extendedDurations {
+		units += ",d,w"
+	}
+	if unit := f.tag.Get("unit"); unit != "" {
+		return fmt.Sprintf("(units: %s, bare numbers in %s)", units, unit)
+	}
+	return fmt.Sprintf("(units: %s)", units)
+}
+
+// parseDuration parses a duration, which may use the extended units of
+// parseExtendedDuration if enabled or, if the field has a unit, be a bare
+// integer in that unit
+func (f *field) parseDuration(value string) (time.Duration, error) {
+	unit, ok, err := f.durationUnit()
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.Duration(n) * unit, nil
+		}
+	}
+	if f.extendedDurations {
+		return parseExtendedDuration(value)
+	}
+	return time.ParseDuration(value)
+}
+
+// unitDuration converts a number, e.g. read from a configuration file, to
+// a duration in the field's unit, returning false if the field has no unit
+// or the value is not a number
+func (f *field) unitDuration(val reflect.Value) (time.Duration, bool) {
+	unit, ok, err := f.durationUnit()
+	if err != nil || !ok {
+		return 0, false
+	}
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return time.Duration(val.Int()) * unit, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return time.Duration(val.Uint()) * unit, true
+	case reflect.Float32, reflect.Float64:
+		return time.Duration(val.Float() * float64(unit)), true
+	}
+	return 0, false
+}
+
+// parse converts the string representation of a value to a value of the
+// field's type. Named types are returned as their basic type, i.e. a
+// `type Level int` is returned as an int. The same parsing is used for
+// default values and for values resolved after the flags are parsed.
+func (f *field) parse(value string) (interface{}, error) {
+	if f.isRaw() {
+		return value, nil
+	}
+
+	switch f.typ {
+	case durationType:
+		return f.parseDuration(value)
+	case ipType:
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address '%s'", value)
+		}
+		return ip, nil
+	case ipMaskType:
+		return parseIPMask(value)
+	case urlType:
+		u, err := url.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		return *u, nil
+	case timeType:
+		return parseTime(value, f.layouts())
+	}
+
+	if e, ok := lookupEnum(f.typ); ok {
+		i, err := e.parse(value)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(i).Convert(basicType(f.typ)).Interface(), nil
+	}
+
+	if isTextType(f.typ) {
+		return parseText(f.typ, value)
+	}
+
+	if f.lenientNumbers && isNumericKind(f.typ.Kind()) {
+		return parseLenientNumber(basicType(f.typ), value)
+	}
+
+	switch f.typ.Kind() {
+	case reflect.String:
+		return value, nil
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 0, f.typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(i).Convert(basicType(f.typ)).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 0, f.typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(u).Convert(basicType(f.typ)).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(value, f.typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(fl).Convert(basicType(f.typ)).Interface(), nil
+	case reflect.Complex64, reflect.Complex128:
+		c, err := parseComplex(value, f.typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(c).Convert(basicType(f.typ)).Interface(), nil
+	case reflect.Map:
+		if !isParseableType(f.typ) {
+			break
+		}
+		return f.parseMap(value)
+	case reflect.Slice:
+		if !isParseableType(f.typ) {
+			break
+		}
+		if isBytesType(f.typ) {
+			return f.parseBytes(value)
+		}
+		elem := f.elem()
+		parts := splitList(value)
+		list := reflect.MakeSlice(basicType(f.typ), len(parts), len(parts))
+		for i, part := range parts {
+			parsed, err := elem.parse(part)
+			if err != nil {
+				return nil, err
+			}
+			list.Index(i).Set(reflect.ValueOf(parsed))
+		}
+		return list.Interface(), nil
+	}
+	return nil, fmt.Errorf("unsupported type '%s'", f.typ)
+}
+
+// decode converts a value resolved by viper to a value of the field's
+// type. Strings, as provided by environment variables and flags, are
+// parsed as per the field's type, while other values are converted.
+func (f *field) decode(raw interface{}) (reflect.Value, error) {
+	if f.isRaw() {
+		return reflect.ValueOf(cast.ToString(raw)), nil
+	}
+	if s, ok := raw.(string); ok {
+		parsed, err := f.parse(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		raw = parsed
+	} else if isJSONType(f.typ) {
+		parsed, err := decodeJSON(f.typ, raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		raw = parsed
+	}
+
+	val := reflect.ValueOf(raw)
+	if val.Type() == f.typ {
+		return val, nil
+	}
+	if f.typ == durationType {
+		if d, ok := f.unitDuration(val); ok {
+			return reflect.ValueOf(d), nil
+		}
+	}
+	if val.Kind() == f.typ.Kind() && val.Type().ConvertibleTo(f.typ) {
+		return val.Convert(f.typ), nil
+	}
+
+	// Fall back to a weak conversion for values such as those read from
+	// a configuration file, parsing any strings encountered, e.g. the
+	// elements of a list, as per their target type
+	out := reflect.New(f.typ)
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       f.decodeHook,
+		WeaklyTypedInput: true,
+		Result:           out.Interface(),
+	})
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if err := decoder.Decode(raw); err != nil {
+		return reflect.Value{}, err
+	}
+	return out.Elem(), nil
+}
+
+// decodeHook is a mapstructure decode hook that parses strings being
+// decoded into a supported type as per that type
+func (f *field) decodeHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if to == durationType {
+		if d, ok := f.unitDuration(reflect.ValueOf(data)); ok {
+			return d, nil
+		}
+	}
+	s, ok := data.(string)
+	if !ok || from.Kind() != reflect.String || !isParseableType(to) {
+		return data, nil
+	}
+	target := *f
+	target.typ = to
+	return target.parse(s)
+}
+
+// formatValue renders the value of a field as a string in the form it
+// would be specified in a default tag. Floating point values are rendered
+// with the precision of a float64 so that values which cannot be exactly
+// represented by a float32 are apparent.
+func formatValue(f *field, value reflect.Value) string {
+	switch f.typ {
+	case durationType:
+		return time.Duration(value.Int()).String()
+	case ipType:
+		return value.Interface().(net.IP).String()
+	case ipMaskType:
+		return formatIPMask(value.Interface().(net.IPMask))
+	case urlType:
+		u := value.Interface().(url.URL)
+		return u.String()
+	case timeType:
+		return value.Interface().(time.Time).Format(f.layouts()[0])
+	}
+
+	if e, ok := lookupEnum(f.typ); ok {
+		return e.name(value.Convert(reflect.TypeOf(int64(0))).Int())
+	}
+
+	if isTextType(f.typ) {
+		if text, ok := formatText(value); ok {
+			return text
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'g', -1, 64)
+	case reflect.Complex64, reflect.Complex128:
+		return formatComplex(value.Complex())
+	case reflect.Slice:
+		if isBytesType(f.typ) {
+			return f.formatBytes(value.Bytes())
+		}
+		elem := f.elem()
+		parts := make([]string, value.Len())
+		for i := range parts {
+			parts[i] = quoteListElement(formatValue(elem, value.Index(i)))
+		}
+		return strings.Join(parts, ",")
+	case reflect.Map:
+		// The entries are sorted by key so that the result is stable
+		pairSep, kvSep := f.mapSeparators()
+		elem := f.elem()
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			parts[i] = key.String() + kvSep + formatValue(elem, value.MapIndex(key))
+		}
+		return strings.Join(parts, pairSep)
+	}
+	return fmt.Sprintf("%v", value.Interface())
+}
@@ -0,0 +1,77 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+type fieldErrorCall struct {
+	path []string
+	err  error
+}
+
+func TestOnFieldErrorWhenBinding(t *testing.T) {
+	var c struct {
+		Server struct {
+			Port int `default:"eighty"`
+		}
+	}
+	var calls []fieldErrorCall
+	_, err := New(&c, WithDefault, WithViper(viper.New()), WithOnFieldError(func(path []string, err error) {
+		calls = append(calls, fieldErrorCall{path, err})
+	}))
+	if err == nil {
+		t.Fatal("expected an error for an invalid default")
+	}
+	if len(calls) != 1 {
+		t.Fatalf("expected the callback to be invoked once, got %d", len(calls))
+	}
+	if want := []string{"Server", "Port"}; !reflect.DeepEqual(calls[0].path, want) {
+		t.Errorf("expected the path %v, got %v", want, calls[0].path)
+	}
+	if !strings.Contains(calls[0].err.Error(), "eighty") {
+		t.Errorf("expected the error to name the default, got '%s'", calls[0].err)
+	}
+}
+
+func TestOnFieldErrorWhenApplying(t *testing.T) {
+	var c struct {
+		Port int
+		Host string
+	}
+	os.Setenv("FE_PORT", "not-a-number")
+	defer os.Unsetenv("FE_PORT")
+
+	var calls []fieldErrorCall
+	p, err := New(&c, WithDefault, WithPrefix("FE"), WithViper(viper.New()), WithOnFieldError(func(path []string, err error) {
+		calls = append(calls, fieldErrorCall{path, err})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err == nil {
+		t.Fatal("expected an error for an invalid environment value")
+	}
+	if len(calls) != 1 || !reflect.DeepEqual(calls[0].path, []string{"Port"}) {
+		t.Errorf("expected a single call for 'Port', got %v", calls)
+	}
+}
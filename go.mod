@@ -3,6 +3,10 @@ module github.com/dbainbri-ciena/venom
 go 1.14
 
 require (
+	github.com/mitchellh/mapstructure v1.4.1
+	github.com/spf13/cast v1.3.1
+	github.com/spf13/cobra v1.1.3
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.8.1
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,93 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// groupAnnotation the flag annotation that records the group, specified by
+// a field's `group` tag, to which its flag belongs
+const groupAnnotation = "venom_group"
+
+// flagGroup returns the group of the flag, or an empty string if the flag
+// belongs to no group
+func flagGroup(flag *pflag.Flag) string {
+	if group := flag.Annotations[groupAnnotation]; len(group) > 0 {
+		return group[0]
+	}
+	return ""
+}
+
+// UsageTemplate returns a usage template, for use with cobra's
+// `Command.SetUsageTemplate`, that lists the flags of the flag set grouped
+// by the `group` tags of the fields to which they are bound, e.g. under a
+// "Database Flags:" heading. Flags that belong to no group are listed first
+// under "Flags:" and the groups follow in alphabetical order. The flag
+// usages are rendered when the template is generated, so it should be
+// generated after all the flags have been defined.
+func UsageTemplate(flagSet *pflag.FlagSet) string {
+	groups := map[string]*pflag.FlagSet{}
+	flagSet.VisitAll(func(flag *pflag.Flag) {
+		group := flagGroup(flag)
+		if _, ok := groups[group]; !ok {
+			groups[group] = pflag.NewFlagSet(group, pflag.ContinueOnError)
+		}
+		groups[group].AddFlag(flag)
+	})
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(`Usage:{{if .Runnable}}
+  {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}{{if gt (len .Aliases) 0}}
+
+Aliases:
+  {{.NameAndAliases}}{{end}}{{if .HasAvailableSubCommands}}
+
+Available Commands:{{range .Commands}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
+  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}
+`)
+	for _, name := range names {
+		usages := groups[name].FlagUsages()
+		if usages == "" {
+			continue
+		}
+		heading := "Flags:"
+		if name != "" {
+			heading = fmt.Sprintf("%s Flags:", name)
+		}
+		// The usages are literal text, so any action delimiters within the
+		// help text must be escaped
+		fmt.Fprintf(&b, "\n%s\n%s", heading, strings.ReplaceAll(usages, "{{", `{{"{{"}}`))
+	}
+	b.WriteString(`{{if .HasAvailableInheritedFlags}}
+Global Flags:
+{{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableSubCommands}}
+
+Use "{{.CommandPath}} [command] --help" for more information about a command.{{end}}
+`)
+	return b.String()
+}
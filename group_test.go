@@ -0,0 +1,107 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// usageCommand provides the fields and methods of a cobra command that the
+// usage template requires for a runnable command without subcommands
+type usageCommand struct {
+	Aliases []string
+}
+
+func (usageCommand) Runnable() bool                   { return true }
+func (usageCommand) UseLine() string                  { return "app [flags]" }
+func (usageCommand) HasAvailableSubCommands() bool    { return false }
+func (usageCommand) HasAvailableInheritedFlags() bool { return false }
+
+func TestUsageTemplate(t *testing.T) {
+	var c struct {
+		Verbose bool   `help:"verbose output"`
+		DBHost  string `name:"db-host" group:"Database" help:"the {{host}} of the database"`
+		DBPort  int    `name:"db-port" group:"Database" default:"5432"`
+		Token   string `group:"Auth"`
+	}
+	flagSet := pflag.NewFlagSet("app", pflag.ContinueOnError)
+	if err := AddConfigurationTo(viper.New(), flagSet, &c, "", DefaultOptions, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The template is executed with the functions that cobra provides
+	tmpl, err := template.New("usage").Funcs(template.FuncMap{
+		"rpad":                    func(s string, n int) string { return fmt.Sprintf(fmt.Sprintf("%%-%ds", n), s) },
+		"trimTrailingWhitespaces": func(s string) string { return strings.TrimRight(s, " \t\n") },
+	}).Parse(UsageTemplate(flagSet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, usageCommand{}); err != nil {
+		t.Fatal(err)
+	}
+	usage := out.String()
+
+	flags := strings.Index(usage, "\nFlags:\n")
+	auth := strings.Index(usage, "\nAuth Flags:\n")
+	database := strings.Index(usage, "\nDatabase Flags:\n")
+	if flags < 0 || auth < 0 || database < 0 {
+		t.Fatalf("expected the ungrouped, Auth, and Database headings, got:\n%s", usage)
+	}
+	if !(flags < auth && auth < database) {
+		t.Errorf("expected the ungrouped flags first and the groups in order, got:\n%s", usage)
+	}
+	for _, tc := range []struct {
+		flag       string
+		start, end int
+	}{
+		{"--verbose", flags, auth},
+		{"--token", auth, database},
+		{"--db-host", database, len(usage)},
+		{"--db-port", database, len(usage)},
+	} {
+		if i := strings.Index(usage, tc.flag); i < tc.start || i > tc.end {
+			t.Errorf("expected '%s' under its group, got:\n%s", tc.flag, usage)
+		}
+	}
+	if !strings.Contains(usage, "the {{host}} of the database") {
+		t.Errorf("expected the help text to be rendered literally, got:\n%s", usage)
+	}
+}
+
+func TestFlagGroup(t *testing.T) {
+	var c struct {
+		Host string `group:"Database"`
+		Port int
+	}
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := AddConfigurationTo(viper.New(), flagSet, &c, "", DefaultOptions, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := flagGroup(flagSet.Lookup("host")); got != "Database" {
+		t.Errorf("expected the group 'Database', got '%s'", got)
+	}
+	if got := flagGroup(flagSet.Lookup("port")); got != "" {
+		t.Errorf("expected no group, got '%s'", got)
+	}
+}
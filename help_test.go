@@ -0,0 +1,320 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestHelpShowsDefaultsAsSpecified(t *testing.T) {
+	var c struct {
+		Address net.IP    `default:"0:0:0:0:0:0:0:1" help:"address to listen on"`
+		Proxy   url.URL   `default:"HTTP://Proxy.Example.COM:3128" help:"proxy to use"`
+		Since   time.Time `default:"2020-01-02T15:04:05+00:00" help:"start of the report"`
+	}
+	viper.Reset()
+	defer viper.Reset()
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := AddConfiguration(flagSet, &c, "", DefaultOptions, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	usage := flagSet.FlagUsages()
+	for _, want := range []string{
+		`address to listen on (default 0:0:0:0:0:0:0:1)`,
+		`proxy to use (default HTTP://Proxy.Example.COM:3128)`,
+		`start of the report (default 2020-01-02T15:04:05+00:00)`,
+	} {
+		if !strings.Contains(usage, want) {
+			t.Errorf("expected the usage to contain '%s', got:\n%s", want, usage)
+		}
+	}
+}
+
+func TestTimeDefault(t *testing.T) {
+	var c struct {
+		Since time.Time `default:"2020-01-02T15:04:05+02:00"`
+	}
+	viper.Reset()
+	defer viper.Reset()
+	if err := AddConfiguration(pflag.NewFlagSet("test", pflag.ContinueOnError), &c, "", DefaultOptions, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2020, 1, 2, 13, 4, 5, 0, time.UTC)
+	if since := viper.GetTime("Since"); !since.Equal(want) {
+		t.Errorf("expected Since to be '%s', got '%s'", want, since)
+	}
+
+	if err := AddConfiguration(pflag.NewFlagSet("test", pflag.ContinueOnError), &struct {
+		Since time.Time `default:"yesterday"`
+	}{}, "", DefaultOptions, nil); err == nil {
+		t.Error("expected an error for a time default that is not RFC3339")
+	}
+}
+
+func TestTypeNamePlaceholder(t *testing.T) {
+	var c struct {
+		Listen  string        `typeName:"host:port" help:"address to listen on"`
+		Timeout time.Duration `typeName:"period" default:"5s" help:"request timeout"`
+		Plain   int           `help:"a plain int"`
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage := p.FlagSet().FlagUsages()
+	for _, want := range []string{"--listen host:port", "--timeout period", "--plain int"} {
+		if !strings.Contains(usage, want) {
+			t.Errorf("expected the usage to contain '%s', got:\n%s", want, usage)
+		}
+	}
+
+	if err := p.Parse([]string{"--timeout=1m"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Timeout != time.Minute {
+		t.Errorf("expected the renamed flag to still set Timeout, got %s", c.Timeout)
+	}
+}
+
+func TestRequireHelp(t *testing.T) {
+	type spec struct {
+		Host    string `help:"host to connect to"`
+		Port    int
+		Timeout time.Duration
+		Cache   string `ignored:"true"`
+	}
+	options := DefaultOptions
+	options.Flags |= WithRequireHelp
+
+	viper.Reset()
+	defer viper.Reset()
+	err := AddConfiguration(pflag.NewFlagSet("test", pflag.ContinueOnError), &spec{}, "", options, nil)
+	if err == nil {
+		t.Fatal("expected an error for flags without help")
+	}
+	if want := "help must be specified for the flags of fields 'Port', 'Timeout'"; err.Error() != want {
+		t.Errorf("expected the error '%s', got '%s'", want, err)
+	}
+
+	// Fields without a flag do not need help
+	options.Flags = GenerateEnv | WithRequireHelp
+	if err := AddConfiguration(pflag.NewFlagSet("test", pflag.ContinueOnError), &spec{}, "", options, nil); err != nil {
+		t.Errorf("expected no error when no flags are generated, got '%s'", err)
+	}
+}
+
+func TestWithoutAutoHelp(t *testing.T) {
+	type spec struct {
+		Port int
+	}
+	tests := []struct {
+		name  string
+		flags Flags
+		args  []string
+		err   error
+	}{
+		{name: "auto help long", flags: WithDefault, args: []string{"--help"}, err: pflag.ErrHelp},
+		{name: "auto help short", flags: WithDefault, args: []string{"-h"}, err: pflag.ErrHelp},
+		{name: "without long", flags: WithDefault | WithoutAutoHelp, args: []string{"--help"}},
+		{name: "without short", flags: WithDefault | WithoutAutoHelp, args: []string{"-h"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := DefaultOptions
+			options.Flags = test.flags
+			flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flagSet.SetOutput(&strings.Builder{})
+			viper.Reset()
+			defer viper.Reset()
+			if err := AddConfiguration(flagSet, &spec{}, "", options, nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := flagSet.Parse(test.args); err != test.err {
+				t.Errorf("expected '%v', got '%v'", test.err, err)
+			}
+		})
+	}
+}
+
+func TestWithoutAutoHelpAllowsHelpField(t *testing.T) {
+	var c struct {
+		Host string `short:"h"`
+	}
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	options := DefaultOptions
+	options.Flags |= WithoutAutoHelp
+	viper.Reset()
+	defer viper.Reset()
+	if err := AddConfiguration(flagSet, &c, "", options, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := flagSet.Parse([]string{"-h", "example.com", "--help"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := flagSet.Lookup("host").Value.String(); got != "example.com" {
+		t.Errorf("expected -h to set --host, got '%s'", got)
+	}
+}
+
+func TestUnitHints(t *testing.T) {
+	var c struct {
+		Timeout time.Duration   `help:"how long to wait"`
+		Retry   time.Duration   `unit:"s"`
+		Delays  []time.Duration `help:"the delays"`
+		Port    int             `help:"the port"`
+	}
+	tests := []struct {
+		flags Flags
+		want  map[string]string
+	}{
+		{WithUnitHints, map[string]string{
+			"timeout": "how long to wait (units: ns,us,ms,s,m,h)",
+			"retry":   "(units: ns,us,ms,s,m,h, bare numbers in s)",
+			"delays":  "the delays (units: ns,us,ms,s,m,h)",
+			"port":    "the port",
+		}},
+		{WithUnitHints | WithExtendedDurations, map[string]string{
+			"timeout": "how long to wait (units: ns,us,ms,s,m,h,d,w)",
+		}},
+		{0, map[string]string{
+			"timeout": "how long to wait",
+			"retry":   "",
+		}},
+	}
+	for _, test := range tests {
+		options := DefaultOptions
+		options.Flags |= test.flags
+		flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		if err := AddConfigurationTo(viper.New(), flagSet, &c, "", options, nil); err != nil {
+			t.Fatal(err)
+		}
+		for name, want := range test.want {
+			if got := flagSet.Lookup(name).Usage; got != want {
+				t.Errorf("expected the help of '--%s' to be '%s', got '%s'", name, want, got)
+			}
+		}
+	}
+}
+
+func TestFloatDefaultNotation(t *testing.T) {
+	var c struct {
+		Rate    float64 `default:"1e6"`
+		Ratio   float32 `default:"0.1"`
+		Small   float64 `default:"0.000001"`
+		None    float64
+		Secret  float64 `default:"2e6" secret:"true"`
+		Integer int     `default:"1000000"`
+	}
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := AddConfigurationTo(viper.New(), flagSet, &c, "", DefaultOptions, nil); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"rate":    "1000000",
+		"ratio":   "0.1",
+		"small":   "0.000001",
+		"none":    "0",
+		"secret":  "****",
+		"integer": "1000000",
+	} {
+		if got := flagSet.Lookup(name).DefValue; got != want {
+			t.Errorf("expected the default of '--%s' to be displayed as '%s', got '%s'", name, want, got)
+		}
+	}
+	if usage := flagSet.FlagUsages(); strings.Contains(usage, "e+06") {
+		t.Errorf("expected no scientific notation in the usage, got:\n%s", usage)
+	}
+}
+
+func TestEnvInUsage(t *testing.T) {
+	type spec struct {
+		Port     int    `help:"port to listen on"`
+		Host     string `env:"LISTEN_HOST"`
+		Password string `secret:"true" help:"password to log in with"`
+	}
+	for _, test := range []struct {
+		name  string
+		flags Flags
+		want  map[string]string
+	}{
+		{
+			name:  "with env",
+			flags: WithDefault | WithEnvInUsage,
+			want: map[string]string{
+				"port":     "port to listen on [env: MYAPP_PORT]",
+				"host":     "[env: MYAPP_LISTEN_HOST]",
+				"password": "password to log in with",
+			},
+		},
+		{
+			name:  "without option",
+			flags: WithDefault,
+			want: map[string]string{
+				"port":     "port to listen on",
+				"host":     "",
+				"password": "password to log in with",
+			},
+		},
+		{
+			name:  "without env",
+			flags: GenerateFlag | WithEnvInUsage,
+			want: map[string]string{
+				"port":     "port to listen on",
+				"host":     "[env: MYAPP_LISTEN_HOST]",
+				"password": "password to log in with",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var c spec
+			p, err := New(&c, test.flags, WithPrefix("MYAPP"), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range test.want {
+				if got := p.FlagSet().Lookup(name).Usage; got != want {
+					t.Errorf("expected the usage of '--%s' to be '%s', got '%s'", name, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvInUsageWithoutViper(t *testing.T) {
+	var c struct {
+		Port int `help:"port to listen on"`
+	}
+	options := DefaultOptions
+	options.Flags |= WithoutViper | WithEnvInUsage
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := AddConfigurationTo(viper.New(), flagSet, &c, "MYAPP", options, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := flagSet.Lookup("port").Usage; got != "port to listen on" {
+		t.Errorf("expected no environment variable in the usage, got '%s'", got)
+	}
+}
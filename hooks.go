@@ -0,0 +1,81 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import "fmt"
+
+// Phase identifies the point in Apply at which a hook is run. The phases
+// are run in the order in which they are defined.
+type Phase int
+
+// Defines the phases at which hooks are run, after the resolved values have
+// been set in the configuration specification
+const (
+	// PhaseNormalize hooks that normalize resolved values, e.g. trimming
+	// or lower casing strings
+	PhaseNormalize Phase = iota
+
+	// PhaseDerive hooks that set values derived from other values
+	PhaseDerive
+
+	// PhaseValidate hooks that validate the configuration, run after the
+	// built in validations
+	PhaseValidate
+)
+
+// String returns the name of the phase
+func (p Phase) String() string {
+	switch p {
+	case PhaseNormalize:
+		return "normalize"
+	case PhaseDerive:
+		return "derive"
+	case PhaseValidate:
+		return "validate"
+	}
+	return fmt.Sprintf("Phase(%d)", int(p))
+}
+
+// HookFunc is run by Apply, at the phase for which it was added, with the
+// configuration specification, returning an error if the hook failed
+type HookFunc func(configSpecification interface{}) error
+
+// hook a function added to run at a phase
+type hook struct {
+	phase Phase
+	fn    HookFunc
+}
+
+// AddHook adds a function to be run by Apply at the given phase. Hooks added
+// for the same phase are run in the order in which they were added.
+func (p *Processor) AddHook(phase Phase, fn HookFunc) {
+	p.hooks = append(p.hooks, hook{phase: phase, fn: fn})
+}
+
+// runHooks runs the hooks added for the given phase, returning the errors
+// of all the hooks that failed
+func (p *Processor) runHooks(phase Phase) Errors {
+	var errs Errors
+	for _, h := range p.hooks {
+		if h.phase != phase {
+			continue
+		}
+		if err := h.fn(p.spec); err != nil {
+			errs = append(errs, fmt.Errorf("%s hook: %w", phase, err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,164 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+type hookSpec struct {
+	Namespace string `default:"  Default "`
+	Address   string
+	Port      int `default:"8080"`
+}
+
+func TestHookPhases(t *testing.T) {
+	var c hookSpec
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var order []string
+	// Hooks are added out of phase order to check they are run in phase
+	// order, and in the order added within a phase
+	p.AddHook(PhaseValidate, func(spec interface{}) error {
+		order = append(order, "validate")
+		if spec.(*hookSpec).Address != "default:8080" {
+			t.Errorf("expected validate to see the derived address, got '%s'", spec.(*hookSpec).Address)
+		}
+		return nil
+	})
+	p.AddHook(PhaseDerive, func(spec interface{}) error {
+		order = append(order, "derive")
+		config := spec.(*hookSpec)
+		config.Address = fmt.Sprintf("%s:%d", config.Namespace, config.Port)
+		return nil
+	})
+	p.AddHook(PhaseNormalize, func(spec interface{}) error {
+		order = append(order, "trim")
+		config := spec.(*hookSpec)
+		config.Namespace = strings.TrimSpace(config.Namespace)
+		return nil
+	})
+	p.AddHook(PhaseNormalize, func(spec interface{}) error {
+		order = append(order, "lower")
+		config := spec.(*hookSpec)
+		config.Namespace = strings.ToLower(config.Namespace)
+		return nil
+	})
+
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(order, ","), "trim,lower,derive,validate"; got != want {
+		t.Errorf("expected hooks to run in the order '%s', got '%s'", want, got)
+	}
+	if c.Namespace != "default" {
+		t.Errorf("expected Namespace to be 'default', got '%s'", c.Namespace)
+	}
+}
+
+func TestHookErrors(t *testing.T) {
+	failure := errors.New("failed")
+	for _, tc := range []struct {
+		name  string
+		phase Phase
+		want  string
+	}{
+		{"normalize", PhaseNormalize, "normalize"},
+		{"derive", PhaseDerive, "normalize,derive"},
+		{"validate", PhaseValidate, "normalize,derive,validate"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var c hookSpec
+			p, err := New(&c, WithDefault, WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(nil); err != nil {
+				t.Fatal(err)
+			}
+			var ran []string
+			for _, phase := range []Phase{PhaseNormalize, PhaseDerive, PhaseValidate} {
+				phase := phase
+				p.AddHook(phase, func(interface{}) error {
+					ran = append(ran, phase.String())
+					if phase == tc.phase {
+						return failure
+					}
+					return nil
+				})
+			}
+
+			err = p.Apply()
+			if !errors.Is(firstError(err), failure) {
+				t.Fatalf("expected the hook error, got '%v'", err)
+			}
+			if want := tc.name + " hook: failed"; firstError(err).Error() != want {
+				t.Errorf("expected the error '%s', got '%s'", want, firstError(err).Error())
+			}
+			if got := strings.Join(ran, ","); got != tc.want {
+				t.Errorf("expected the phases '%s' to run, got '%s'", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateHookAggregatesErrors(t *testing.T) {
+	var c struct {
+		Name string `required:"true"`
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	p.AddHook(PhaseValidate, func(interface{}) error {
+		return errors.New("invalid")
+	})
+
+	err = p.Apply()
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expected the required and hook errors, got '%v'", err)
+	}
+	if !strings.Contains(errs[1].Error(), "validate hook: invalid") {
+		t.Errorf("expected the hook error last, got '%v'", errs[1])
+	}
+}
+
+func TestPhaseString(t *testing.T) {
+	for phase, want := range map[Phase]string{
+		PhaseNormalize: "normalize",
+		PhaseDerive:    "derive",
+		PhaseValidate:  "validate",
+		Phase(7):       "Phase(7)",
+	} {
+		if got := phase.String(); got != want {
+			t.Errorf("expected '%s', got '%s'", want, got)
+		}
+	}
+}
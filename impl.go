@@ -0,0 +1,144 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"reflect"
+	"sync"
+)
+
+// implementationKey identifies a registered implementation of an interface
+type implementationKey struct {
+	iface reflect.Type
+	name  string
+}
+
+var (
+	implementationsMu sync.RWMutex
+	implementations   = map[implementationKey]func() interface{}{}
+)
+
+// RegisterImplementation registers a named implementation of an interface
+// type that can be selected for members of that type using the `impl` tag,
+// e.g. `impl:"s3"`. The build function returns a pointer to a new struct
+// that implements the interface, the fields of which are processed as
+// those of a nested struct, e.g.
+//
+//	venom.RegisterImplementation(reflect.TypeOf((*Backend)(nil)).Elem(), "s3",
+//	    func() interface{} { return &S3Backend{} })
+//
+// Registering an implementation with the same name as an existing
+// implementation of the interface replaces it.
+func RegisterImplementation(iface reflect.Type, name string, build func() interface{}) {
+	implementationsMu.Lock()
+	defer implementationsMu.Unlock()
+	implementations[implementationKey{iface: iface, name: name}] = build
+}
+
+// lookupImplementation returns the build function of the implementation of
+// the interface registered with the given name
+func lookupImplementation(iface reflect.Type, name string) (func() interface{}, bool) {
+	implementationsMu.RLock()
+	defer implementationsMu.RUnlock()
+	build, ok := implementations[implementationKey{iface: iface, name: name}]
+	return build, ok
+}
+
+// isImplementation returns true if the struct field is of an interface
+// type and selects an implementation using the `impl` tag
+func isImplementation(fieldType reflect.StructField) bool {
+	return fieldType.Type.Kind() == reflect.Interface && fieldType.Tag.Get("impl") != ""
+}
+
+// implementation is the implementation of an interface field selected by
+// its `impl` tag
+type implementation struct {
+	// index the index of the interface field within the specification
+	index []int
+	typ   reflect.Type
+	build func() interface{}
+}
+
+// selectImplementation returns the implementation, selected by its `impl`
+// tag, of an interface field together with the struct of a new instance of
+// the implementation, whose fields are described in place of the field.
+// The field itself is not modified, see installImplementations.
+func selectImplementation(f *field) (*implementation, reflect.Value, error) {
+	name := f.tag.Get("impl")
+	build, ok := lookupImplementation(f.typ, name)
+	if !ok {
+		return nil, reflect.Value{}, configErrorf(f, "impl", "unknown implementation '%s' of '%s'", name, f.typ)
+	}
+	built := reflect.ValueOf(build())
+	if built.Kind() != reflect.Ptr || built.IsNil() || built.Elem().Kind() != reflect.Struct {
+		return nil, reflect.Value{}, configErrorf(f, "impl", "implementation '%s' of '%s' must be a pointer to a struct", name, f.typ)
+	}
+	if !built.Type().Implements(f.typ) {
+		return nil, reflect.Value{}, configErrorf(f, "impl", "implementation '%s' does not implement '%s'", name, f.typ)
+	}
+	return &implementation{index: f.index, typ: built.Type(), build: build}, built.Elem(), nil
+}
+
+// describeImplementations returns the distinct implementations selected
+// by the interface fields of the configSpecification
+func describeImplementations(configSpecification interface{}, prefix string, options ProcessingOptions) ([]*implementation, error) {
+	all, err := describeAllFields(configSpecification, prefix, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var impls []*implementation
+	seen := map[*implementation]bool{}
+	for _, f := range all {
+		if f.implementation != nil && !seen[f.implementation] {
+			seen[f.implementation] = true
+			impls = append(impls, f.implementation)
+		}
+	}
+	return impls, nil
+}
+
+// installed returns true if the interface field within the struct value
+// holds an implementation of the selected type
+func (impl *implementation) installed(specElem reflect.Value) bool {
+	value := specElem.FieldByIndex(impl.index)
+	return !value.IsNil() && value.Elem().Type() == impl.typ
+}
+
+// installImplementations sets each interface field that does not already
+// hold an implementation of the selected type to a new one, so that the
+// values of its fields can be set
+func installImplementations(specElem reflect.Value, impls []*implementation) {
+	for _, impl := range impls {
+		if !impl.installed(specElem) {
+			specElem.FieldByIndex(impl.index).Set(reflect.ValueOf(impl.build()))
+		}
+	}
+}
+
+// fieldByIndex returns the nested field of the struct value with the given
+// index, as per reflect.Value.FieldByIndex, traversing the implementation
+// held by an interface field selected using the `impl` tag, which must have
+// been installed, see installImplementations
+func fieldByIndex(value reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if value.Kind() == reflect.Interface {
+			value = value.Elem().Elem()
+		}
+		value = value.Field(i)
+	}
+	return value
+}
@@ -0,0 +1,188 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+type implStore interface {
+	Name() string
+}
+
+type implS3 struct {
+	Bucket string `default:"logs"`
+	Region string `default:"us-east-1"`
+}
+
+func (*implS3) Name() string { return "s3" }
+
+type implDisk struct {
+	Path string `default:"/var/lib/data"`
+}
+
+func (*implDisk) Name() string { return "disk" }
+
+type implMemory struct{}
+
+func (*implMemory) Name() string { return "memory" }
+
+func init() {
+	iface := reflect.TypeOf((*implStore)(nil)).Elem()
+	RegisterImplementation(iface, "s3", func() interface{} { return &implS3{} })
+	RegisterImplementation(iface, "disk", func() interface{} { return &implDisk{} })
+	RegisterImplementation(iface, "memory", func() interface{} { return &implMemory{} })
+}
+
+func TestImplementationSelection(t *testing.T) {
+	var s3 struct {
+		Store implStore `impl:"s3"`
+	}
+	var disk struct {
+		Store implStore `impl:"disk"`
+	}
+
+	tests := []struct {
+		name  string
+		spec  interface{}
+		args  []string
+		flags []string
+	}{
+		{name: "s3", spec: &s3, args: []string{"--store-bucket", "audit"}, flags: []string{"store-bucket", "store-region"}},
+		{name: "disk", spec: &disk, args: []string{"--store-path", "/tmp/data"}, flags: []string{"store-path"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := New(test.spec, WithFlag(), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var flags []string
+			p.FlagSet().VisitAll(func(flag *pflag.Flag) {
+				flags = append(flags, flag.Name)
+			})
+			if !reflect.DeepEqual(flags, test.flags) {
+				t.Errorf("expected flags %v, got %v", test.flags, flags)
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	if s3.Store == nil || s3.Store.Name() != "s3" {
+		t.Fatalf("expected the s3 implementation to be installed, got %#v", s3.Store)
+	}
+	if got := s3.Store.(*implS3); got.Bucket != "audit" || got.Region != "us-east-1" {
+		t.Errorf("unexpected s3 values %+v", got)
+	}
+	if disk.Store == nil || disk.Store.Name() != "disk" {
+		t.Fatalf("expected the disk implementation to be installed, got %#v", disk.Store)
+	}
+	if got := disk.Store.(*implDisk); got.Path != "/tmp/data" {
+		t.Errorf("unexpected disk values %+v", got)
+	}
+}
+
+func TestImplementationDescribeLeavesSpecUnchanged(t *testing.T) {
+	var c struct {
+		Store implStore `impl:"s3"`
+	}
+
+	if _, err := Defaults(&c, "", DefaultOptions); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DescribeConfiguration(&c, "", DefaultOptions); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Lint(&c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GenerateCompletion(&c, "", DefaultOptions, "bash"); err != nil {
+		t.Fatal(err)
+	}
+	v := viper.New()
+	if err := AddConfigurationTo(v, pflag.NewFlagSet("test", pflag.ContinueOnError), &c, "", DefaultOptions, nil); err != nil {
+		t.Fatal(err)
+	}
+	if c.Store != nil {
+		t.Fatalf("expected the member to be left nil, got %#v", c.Store)
+	}
+
+	if err := PopulateFrom(v, &c, "", DefaultOptions); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := c.Store.(*implS3); !ok || got.Bucket != "logs" {
+		t.Errorf("expected Populate to install the s3 implementation with its defaults, got %#v", c.Store)
+	}
+}
+
+func TestImplementationKeepsExistingInstance(t *testing.T) {
+	existing := &implS3{Region: "eu-west-1"}
+	c := struct {
+		Store implStore `impl:"s3"`
+	}{Store: existing}
+
+	p, err := New(&c, WithFlag(), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--store-bucket", "audit"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Store != existing {
+		t.Fatal("expected the existing implementation to be kept")
+	}
+	if existing.Bucket != "audit" {
+		t.Errorf("expected the bucket to be set, got '%s'", existing.Bucket)
+	}
+}
+
+func TestImplementationWithoutConfiguration(t *testing.T) {
+	var c struct {
+		Store implStore `impl:"memory"`
+	}
+
+	p, err := New(&c, WithFlag(), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Store.(*implMemory); !ok {
+		t.Errorf("expected the memory implementation to be installed, got %#v", c.Store)
+	}
+}
+
+func TestUnknownImplementation(t *testing.T) {
+	var c struct {
+		Store implStore `impl:"gcs"`
+	}
+	if _, err := Defaults(&c, "", DefaultOptions); err == nil {
+		t.Error("expected an error for an unknown implementation")
+	}
+}
@@ -0,0 +1,89 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// isJSONType returns true if a pointer to the type implements
+// json.Unmarshaler but not encoding.TextUnmarshaler, in which case values
+// are parsed as JSON by parseJSON
+func isJSONType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		return false
+	}
+	ptr := reflect.PtrTo(typ)
+	return ptr.Implements(jsonUnmarshalerType) && !ptr.Implements(textUnmarshalerType)
+}
+
+// parseJSON parses the value as a value of the given type using its
+// UnmarshalJSON method. A value that is valid JSON, e.g. `42` or
+// `{"a":1}`, is unmarshaled as is, while any other value, e.g. `5s`, is
+// first wrapped in a JSON string.
+func parseJSON(typ reflect.Type, value string) (interface{}, error) {
+	data := []byte(value)
+	if !json.Valid(data) {
+		data = []byte(strconv.Quote(value))
+	}
+	ptr := reflect.New(typ)
+	if err := ptr.Interface().(json.Unmarshaler).UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// decodeJSON converts a value, such as a map or number read from a
+// configuration file, to a value of the given type by unmarshaling its JSON
+// encoding. A value that already has the type is returned unchanged.
+func decodeJSON(typ reflect.Type, raw interface{}) (interface{}, error) {
+	if reflect.TypeOf(raw) == typ {
+		return raw, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	return parseJSON(typ, string(data))
+}
+
+// formatJSON renders the value pointed to as JSON, using its MarshalJSON
+// method if the type implements json.Marshaler or the default encoding if
+// it is otherwise a JSON type, so that the rendering can be parsed back by
+// parseJSON, returning false otherwise. A JSON string is rendered without
+// its quotes, as it would be specified.
+func formatJSON(ptr reflect.Value) (string, bool) {
+	if !ptr.Type().Implements(jsonMarshalerType) && !isJSONType(ptr.Type().Elem()) {
+		return "", false
+	}
+	data, err := json.Marshal(ptr.Interface())
+	if err != nil {
+		return fmt.Sprintf("%v", ptr.Elem().Interface()), true
+	}
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		return s, true
+	}
+	return string(data), true
+}
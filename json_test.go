@@ -0,0 +1,150 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// timeout a duration that, in JSON, is either a number of seconds or a
+// duration string, and only implements json.Unmarshaler and json.Marshaler
+type timeout time.Duration
+
+func (d *timeout) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		*d = timeout(seconds * float64(time.Second))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = timeout(parsed)
+	return nil
+}
+
+func (d timeout) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// limits a structure that is configured as a JSON object
+type limits struct {
+	Max   int
+	Burst int
+}
+
+func (l *limits) UnmarshalJSON(data []byte) error {
+	type plain limits
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Burst < p.Max {
+		p.Burst = p.Max
+	}
+	*l = limits(p)
+	return nil
+}
+
+type jsonSpec struct {
+	Timeout timeout `default:"5s"`
+	Limits  limits  `default:"{\"max\": 2}"`
+}
+
+func TestJSONUnmarshalerTypes(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		args    []string
+		file    string
+		timeout time.Duration
+		limits  limits
+	}{
+		{name: "default", timeout: 5 * time.Second, limits: limits{Max: 2, Burst: 2}},
+		{name: "flag", args: []string{"--timeout=10", `--limits={"max": 3, "burst": 5}`}, timeout: 10 * time.Second, limits: limits{Max: 3, Burst: 5}},
+		{name: "flag string", args: []string{"--timeout=1m"}, timeout: time.Minute, limits: limits{Max: 2, Burst: 2}},
+		{name: "file", file: "timeout: 30\nlimits:\n  max: 4\n", timeout: 30 * time.Second, limits: limits{Max: 4, Burst: 4}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			options := []Option{WithDefault, WithViper(viper.New())}
+			if test.file != "" {
+				options = append(options, WithConfigReader(strings.NewReader(test.file), "yaml"))
+			}
+			var c jsonSpec
+			p, err := New(&c, options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if time.Duration(c.Timeout) != test.timeout {
+				t.Errorf("expected Timeout to be '%s', got '%s'", test.timeout, time.Duration(c.Timeout))
+			}
+			if c.Limits != test.limits {
+				t.Errorf("expected Limits to be %+v, got %+v", test.limits, c.Limits)
+			}
+		})
+	}
+}
+
+func TestJSONUnmarshalerUsage(t *testing.T) {
+	var c jsonSpec
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage := p.FlagSet().FlagUsages()
+	for _, want := range []string{"--timeout timeout", "(default 5s)", "--limits limits", `(default {"Max":2,"Burst":2})`} {
+		if !strings.Contains(usage, want) {
+			t.Errorf("expected the usage to contain '%s', got:\n%s", want, usage)
+		}
+	}
+
+	p.FlagSet().SetOutput(&strings.Builder{})
+	if err := p.Parse([]string{"--timeout=soon"}); err == nil {
+		t.Error("expected an error for a value that cannot be unmarshaled")
+	}
+}
+
+func TestIsJSONType(t *testing.T) {
+	for _, test := range []struct {
+		typ  reflect.Type
+		want bool
+	}{
+		{reflect.TypeOf(timeout(0)), true},
+		{reflect.TypeOf(limits{}), true},
+		{reflect.TypeOf(time.Duration(0)), false},
+		{reflect.TypeOf(net.IP{}), false},
+	} {
+		if got := isJSONType(test.typ); got != test.want {
+			t.Errorf("expected isJSONType(%s) to be %t, got %t", test.typ, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestKeyTags(t *testing.T) {
+	type spec struct {
+		Port  int `key:"server.port" default:"80"`
+		Other int `key:"other" readKey:"listen_port" default:"1"`
+	}
+
+	t.Run("key", func(t *testing.T) {
+		var c spec
+		v := viper.New()
+		p, err := New(&c, WithDefault, WithViper(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Parse([]string{"--port=8080"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := v.GetInt("server.port"); got != 8080 {
+			t.Errorf("expected the flag to be bound to 'server.port', got %d", got)
+		}
+		if err := p.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		if c.Port != 8080 {
+			t.Errorf("expected Port to be 8080, got %d", c.Port)
+		}
+	})
+
+	t.Run("readKey", func(t *testing.T) {
+		var c spec
+		v := viper.New()
+		v.SetConfigType("yaml")
+		if err := v.ReadConfig(strings.NewReader("listen_port: 9090\nother: 7\n")); err != nil {
+			t.Fatal(err)
+		}
+		p, err := New(&c, WithDefault, WithViper(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Parse([]string{"--other=5"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		if c.Other != 9090 {
+			t.Errorf("expected Other to be read from 'listen_port', got %d", c.Other)
+		}
+	})
+}
+
+func TestKeyNamespace(t *testing.T) {
+	type db struct {
+		Host string `default:"db.local"`
+		Port int    `key:"listen" default:"5432"`
+	}
+	type cache struct {
+		Host string `default:"cache.local"`
+	}
+
+	v := viper.New()
+	var d db
+	var c cache
+	dp, err := New(&d, WithDefault, WithPrefix("DB"), WithKeyNamespace("db"), WithViper(v), WithFlagSet(pflag.NewFlagSet("db", pflag.ContinueOnError)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp, err := New(&c, WithDefault, WithPrefix("CACHE"), WithKeyNamespace("cache"), WithViper(v), WithFlagSet(pflag.NewFlagSet("cache", pflag.ContinueOnError)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]interface{}{"db.host": "db.local", "db.listen": 5432, "cache.host": "cache.local"} {
+		if got := v.Get(key); got != want {
+			t.Errorf("expected '%s' to be '%v', got '%v'", key, want, got)
+		}
+	}
+	if v.IsSet("host") {
+		t.Error("expected no key outside the namespaces")
+	}
+	if dp.FlagSet().Lookup("host") == nil {
+		t.Error("expected the flag not to be namespaced")
+	}
+	if err := dp.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if d.Host != "db.local" || d.Port != 5432 || c.Host != "cache.local" {
+		t.Errorf("expected each specification to read its own keys, got %+v and %+v", d, c)
+	}
+}
+
+func TestFlatKeys(t *testing.T) {
+	type spec struct {
+		Server struct {
+			Host string `default:"localhost"`
+			Port int    `default:"80"`
+		}
+	}
+	var c spec
+	v := viper.New()
+	p, err := New(&c, WithDefault, WithFlatKeys, WithPrefix("FLAT"), WithViper(v),
+		WithConfigReader(strings.NewReader("host: example.com\n"), "yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.FlagSet().Lookup("server-port") == nil {
+		t.Error("expected the flag to keep its prefix")
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Host != "example.com" || c.Server.Port != 80 {
+		t.Errorf("expected the members to be read from flat keys, got %+v", c.Server)
+	}
+}
+
+func TestFlatKeyCollisions(t *testing.T) {
+	var collide struct {
+		Server struct{ Host string }
+		Client struct{ Host string }
+	}
+	_, err := New(&collide, WithDefault, WithFlatKeys, WithViper(viper.New()))
+	if err == nil || !strings.Contains(err.Error(), "'Server.Host'") || !strings.Contains(err.Error(), "'Client.Host'") {
+		t.Errorf("expected a collision naming both fields, got '%v'", err)
+	}
+
+	var explicit struct {
+		Server struct {
+			Host string `key:"host"`
+		}
+		Client struct {
+			Host string `key:"host"`
+		}
+	}
+	if _, err := New(&explicit, WithDefault, WithFlatKeys, WithViper(viper.New())); err != nil {
+		t.Errorf("expected explicit keys to be allowed to collide, got '%s'", err)
+	}
+}
+
+func TestKeyCase(t *testing.T) {
+	type spec struct {
+		ListenPort int    `default:"80"`
+		Name       string `key:"Server.Name" default:"web"`
+	}
+	tests := []struct {
+		flags Flags
+		want  map[string]bool
+	}{
+		{flags: WithDefault, want: map[string]bool{"listenport": true, "server.name": true}},
+		{flags: WithDefault | WithCaseSensitiveKeys, want: map[string]bool{"ListenPort": true, "Server.Name": true}},
+	}
+	for _, test := range tests {
+		options := DefaultOptions
+		options.Flags = test.flags
+		defaults, err := Defaults(&spec{}, "", options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]bool{}
+		for key := range defaults {
+			got[key] = true
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("expected the keys %v, got %v", test.want, got)
+		}
+
+		// Viper compares keys case insensitively either way
+		v := viper.New()
+		if _, err := New(&spec{}, test.flags, WithViper(v)); err != nil {
+			t.Fatal(err)
+		}
+		if v.GetString("SERVER.NAME") != "web" || v.GetInt("listenport") != 80 {
+			t.Errorf("expected viper to resolve the keys case insensitively, got %v", v.AllSettings())
+		}
+	}
+}
@@ -0,0 +1,99 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// EnvTransformFunc transforms the value of a legacy environment variable
+// into the form expected of the current environment variable
+type EnvTransformFunc func(value string) (string, error)
+
+var (
+	envTransformsMu sync.RWMutex
+	envTransforms   = map[string]EnvTransformFunc{
+		"lower": func(value string) (string, error) { return strings.ToLower(value), nil },
+		"upper": func(value string) (string, error) { return strings.ToUpper(value), nil },
+		"trim":  func(value string) (string, error) { return strings.TrimSpace(value), nil },
+
+		"prefixStrip": stripScheme,
+	}
+)
+
+// RegisterEnvTransform registers a named transform that can be referenced
+// from an `envLegacyTransform` tag. The `lower`, `upper`, `trim`, and
+// `prefixStrip` transforms are registered by default.
+func RegisterEnvTransform(name string, fn EnvTransformFunc) {
+	envTransformsMu.Lock()
+	defer envTransformsMu.Unlock()
+	envTransforms[name] = fn
+}
+
+// stripScheme removes the scheme prefix, e.g. `tcp://`, of a URL, as is
+// often included in legacy endpoint variables, leaving the address. A
+// value without a scheme is returned unchanged.
+func stripScheme(value string) (string, error) {
+	if i := strings.Index(value, "://"); i >= 0 {
+		return value[i+3:], nil
+	}
+	return value, nil
+}
+
+// lookupEnvTransform returns the transform registered with the given name
+func lookupEnvTransform(name string) (EnvTransformFunc, bool) {
+	envTransformsMu.RLock()
+	defer envTransformsMu.RUnlock()
+	fn, ok := envTransforms[name]
+	return fn, ok
+}
+
+// legacyEnv returns the value of the field's legacy environment variable,
+// specified by the `envLegacy` tag, if it is set and the field's current
+// environment variable is not
+func (f *field) legacyEnv() (string, bool) {
+	legacy := f.tag.Get("envLegacy")
+	if legacy == "" {
+		return "", false
+	}
+	if value, ok := os.LookupEnv(f.env); ok && value != "" {
+		return "", false
+	}
+	value, ok := os.LookupEnv(legacy)
+	return value, ok && value != ""
+}
+
+// transformLegacy resolves the value provided by the field's legacy
+// environment variable and applies the transform named by the
+// `envLegacyTransform` tag, if any
+func (f *field) transformLegacy(value string) (string, error) {
+	value, err := resolveValue(value)
+	if err != nil {
+		return "", err
+	}
+	name := f.tag.Get("envLegacyTransform")
+	if name == "" {
+		return value, nil
+	}
+	fn, ok := lookupEnvTransform(name)
+	if !ok {
+		return "", fmt.Errorf("unknown environment transform '%s'", name)
+	}
+	return fn(value)
+}
@@ -0,0 +1,134 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLegacyEnv(t *testing.T) {
+	type spec struct {
+		Endpoint string `env:"NEW_ENDPOINT" envLegacy:"OLD_ENDPOINT_URL" envLegacyTransform:"prefixStrip"`
+	}
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		precedence []Source
+		want       string
+	}{
+		{
+			name: "legacy only",
+			env:  map[string]string{"OLD_ENDPOINT_URL": "tcp://old:1"},
+			want: "old:1",
+		},
+		{
+			name: "new only",
+			env:  map[string]string{"NEW_ENDPOINT": "tcp://new:2"},
+			want: "tcp://new:2",
+		},
+		{
+			name: "both set",
+			env:  map[string]string{"NEW_ENDPOINT": "new:2", "OLD_ENDPOINT_URL": "tcp://old:1"},
+			want: "new:2",
+		},
+		{
+			name: "new set empty",
+			env:  map[string]string{"NEW_ENDPOINT": "", "OLD_ENDPOINT_URL": "tcp://old:1"},
+			want: "old:1",
+		},
+		{
+			name:       "legacy only with precedence",
+			env:        map[string]string{"OLD_ENDPOINT_URL": "tcp://old:1"},
+			precedence: []Source{FlagSource, EnvSource, FileSource, DefaultSource},
+			want:       "old:1",
+		},
+		{
+			name:       "both set with precedence",
+			env:        map[string]string{"NEW_ENDPOINT": "new:2", "OLD_ENDPOINT_URL": "tcp://old:1"},
+			precedence: []Source{FlagSource, EnvSource, FileSource, DefaultSource},
+			want:       "new:2",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+
+			var c spec
+			opts := []Option{WithEnv(), WithViper(viper.New())}
+			if test.precedence != nil {
+				opts = append(opts, WithPrecedence(test.precedence))
+			}
+			p, err := New(&c, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if c.Endpoint != test.want {
+				t.Errorf("expected '%s', got '%s'", test.want, c.Endpoint)
+			}
+		})
+	}
+}
+
+func TestEnvTransforms(t *testing.T) {
+	tests := []struct {
+		transform, value, want string
+	}{
+		{"lower", "DEBUG", "debug"},
+		{"upper", "debug", "DEBUG"},
+		{"trim", "  debug\n", "debug"},
+		{"prefixStrip", "tcp://db:5432", "db:5432"},
+		{"prefixStrip", "https://example.com/path", "example.com/path"},
+		{"prefixStrip", "db:5432", "db:5432"},
+	}
+	for _, test := range tests {
+		fn, ok := lookupEnvTransform(test.transform)
+		if !ok {
+			t.Fatalf("transform '%s' is not registered", test.transform)
+		}
+		got, err := fn(test.value)
+		if err != nil {
+			t.Errorf("%s(%q): unexpected error: %v", test.transform, test.value, err)
+		} else if got != test.want {
+			t.Errorf("%s(%q): expected %q, got %q", test.transform, test.value, test.want, got)
+		}
+	}
+}
+
+func TestUnknownEnvTransform(t *testing.T) {
+	os.Setenv("OLD_LEVEL", "info")
+	defer os.Unsetenv("OLD_LEVEL")
+
+	var c struct {
+		Level string `env:"LEVEL" envLegacy:"OLD_LEVEL" envLegacyTransform:"missing"`
+	}
+	p, err := New(&c, WithEnv(), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err == nil {
+		t.Error("expected an error for an unknown transform")
+	}
+}
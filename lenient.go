@@ -0,0 +1,90 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// parseLenientNumber parses a string, e.g. a quoted number templated into
+// a configuration file, as a number of the given numeric type, ignoring
+// surrounding whitespace. An integer may be written in any base accepted by
+// strconv.ParseInt, e.g. `0x1f`, or in floating point notation, e.g. `1e3`
+// or `8080.0`, if the value is integral.
+func parseLenientNumber(typ reflect.Type, value string) (interface{}, error) {
+	value = strings.TrimSpace(value)
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 0, typ.Bits())
+		if err != nil {
+			fl, ferr := strconv.ParseFloat(value, 64)
+			if ferr != nil || fl != math.Trunc(fl) || fl < math.MinInt64 || fl >= math.MaxInt64 {
+				return nil, err
+			}
+			i, err = int64(fl), nil
+			if reflect.Zero(typ).OverflowInt(i) {
+				return nil, fmt.Errorf("value '%s' overflows %s", value, typ)
+			}
+		}
+		return reflect.ValueOf(i).Convert(typ).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 0, typ.Bits())
+		if err != nil {
+			fl, ferr := strconv.ParseFloat(value, 64)
+			if ferr != nil || fl != math.Trunc(fl) || fl < 0 || fl >= math.MaxUint64 {
+				return nil, err
+			}
+			u, err = uint64(fl), nil
+			if reflect.Zero(typ).OverflowUint(u) {
+				return nil, fmt.Errorf("value '%s' overflows %s", value, typ)
+			}
+		}
+		return reflect.ValueOf(u).Convert(typ).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(value, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(fl).Convert(typ).Interface(), nil
+	}
+	return nil, fmt.Errorf("unsupported type '%s'", typ)
+}
+
+// StringToNumberHookFunc returns a mapstructure decode hook that converts
+// strings to numeric types as described for WithLenientNumbers, e.g. for
+// use with viper.Unmarshal:
+//
+//	viper.Unmarshal(&config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+//	    venom.StringToNumberHookFunc(),
+//	    mapstructure.StringToTimeDurationHookFunc(),
+//	    mapstructure.StringToSliceHookFunc(","))))
+//
+// Empty strings and values of other types are left unchanged.
+func StringToNumberHookFunc() mapstructure.DecodeHookFuncType {
+	return func(from, to reflect.Type, data interface{}) (interface{}, error) {
+		s, ok := data.(string)
+		if !ok || strings.TrimSpace(s) == "" || !isNumericKind(to.Kind()) {
+			return data, nil
+		}
+		return parseLenientNumber(to, s)
+	}
+}
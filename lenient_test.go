@@ -0,0 +1,126 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+func TestParseLenientNumber(t *testing.T) {
+	for _, test := range []struct {
+		typ   reflect.Type
+		value string
+		want  interface{}
+	}{
+		{reflect.TypeOf(0), " 8080 ", 8080},
+		{reflect.TypeOf(0), "1e3", 1000},
+		{reflect.TypeOf(0), "8080.0", 8080},
+		{reflect.TypeOf(0), "0x1f", 31},
+		{reflect.TypeOf(int8(0)), "-128", int8(-128)},
+		{reflect.TypeOf(uint16(0)), "\t6.5e4\n", uint16(65000)},
+		{reflect.TypeOf(float32(0)), " 0.5 ", float32(0.5)},
+		{reflect.TypeOf(0.0), "1e-3", 0.001},
+	} {
+		got, err := parseLenientNumber(test.typ, test.value)
+		if err != nil {
+			t.Errorf("%q as %s: unexpected error '%s'", test.value, test.typ, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%q as %s: expected %v (%T), got %v (%T)", test.value, test.typ, test.want, test.want, got, got)
+		}
+	}
+}
+
+func TestParseLenientNumberErrors(t *testing.T) {
+	for _, test := range []struct {
+		typ   reflect.Type
+		value string
+	}{
+		{reflect.TypeOf(0), "1.5"},
+		{reflect.TypeOf(0), "eighty"},
+		{reflect.TypeOf(int8(0)), "1e3"},
+		{reflect.TypeOf(uint(0)), "-1"},
+		{reflect.TypeOf(uint8(0)), "256.0"},
+		{reflect.TypeOf(0.0), "half"},
+		{reflect.TypeOf(""), "1"},
+	} {
+		if got, err := parseLenientNumber(test.typ, test.value); err == nil {
+			t.Errorf("%q as %s: expected an error, got %v", test.value, test.typ, got)
+		}
+	}
+}
+
+func TestWithLenientNumbers(t *testing.T) {
+	type spec struct {
+		Port    int     `default:" 80 "`
+		Workers uint    `default:"4"`
+		Ratio   float64 `default:"0.5"`
+	}
+	file := "port: \" 8080 \"\nworkers: \"1e2\"\nratio: \" 0.25 \"\n"
+
+	var c spec
+	p, err := New(&c, WithDefault|WithLenientNumbers, WithViper(viper.New()),
+		WithConfigReader(strings.NewReader(file), "yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Port != 8080 || c.Workers != 100 || c.Ratio != 0.25 {
+		t.Errorf("expected 8080, 100, and 0.25, got %d, %d, and %v", c.Port, c.Workers, c.Ratio)
+	}
+
+	var strict spec
+	if _, err := New(&strict, WithDefault, WithViper(viper.New())); err == nil {
+		t.Error("expected an error for a default with whitespace without WithLenientNumbers")
+	}
+}
+
+func TestStringToNumberHookFunc(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader("port: \" 8080 \"\nretries: \"1e1\"\nname: api\nratio: \"\"\n")); err != nil {
+		t.Fatal(err)
+	}
+	var c struct {
+		Port    int
+		Retries int8
+		Name    string
+		Ratio   float64
+	}
+	if err := v.Unmarshal(&c, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		StringToNumberHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc()))); err != nil {
+		t.Fatal(err)
+	}
+	if c.Port != 8080 || c.Retries != 10 || c.Name != "api" {
+		t.Errorf("expected 8080, 10, and 'api', got %d, %d, and '%s'", c.Port, c.Retries, c.Name)
+	}
+
+	v.Set("port", "eighty")
+	if err := v.Unmarshal(&c, viper.DecodeHook(StringToNumberHookFunc())); err == nil {
+		t.Error("expected an error for a value that is not a number")
+	}
+}
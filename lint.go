@@ -0,0 +1,233 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Severity indicates how serious a problem found by Lint is
+type Severity int
+
+// Defines the severities of the problems found by Lint
+const (
+	// LintWarning a problem that does not prevent the specification from
+	// being processed, but likely does not behave as intended
+	LintWarning Severity = iota
+
+	// LintError a problem that causes processing of the specification to
+	// fail or panic
+	LintError
+)
+
+// String returns the name of the severity
+func (s Severity) String() string {
+	switch s {
+	case LintWarning:
+		return "warning"
+	case LintError:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// LintIssue describes a problem found in a configuration specification
+type LintIssue struct {
+	Severity Severity
+	Field    string
+	Message  string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: field '%s': %s", i.Severity, i.Field, i.Message)
+}
+
+// foreignTagNames the structure tags commonly used by other packages that
+// are not reported as unknown by Lint
+var foreignTagNames = []string{"json", "yaml", "mapstructure", "toml"}
+
+// Lint statically inspects the configSpecification, as it would be
+// processed using DefaultOptions, for problems without binding anything to
+// viper or pflag. The issues are returned in the order of the fields in the
+// specification.
+func Lint(configSpecification interface{}) ([]LintIssue, error) {
+	fields, err := describeFields(configSpecification, "", DefaultOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []LintIssue
+	report := func(f *field, severity Severity, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{
+			Severity: severity,
+			Field:    f.name,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	longs := map[string]string{}
+	shorts := map[string]string{}
+	envs := map[string]string{}
+	keys := map[string]string{}
+	duplicate := func(f *field, seen map[string]string, kind, name string) {
+		if name == "" {
+			return
+		}
+		if other, ok := seen[strings.ToLower(name)]; ok {
+			report(f, LintError, "%s '%s' is also used by field '%s'", kind, name, other)
+		}
+		seen[strings.ToLower(name)] = f.name
+	}
+
+	positionals := map[int]string{}
+	for _, f := range fields {
+		for _, name := range tagKeys(f.tag) {
+			if !knownTagName(name) {
+				report(f, LintWarning, "unknown tag '%s'", name)
+			}
+		}
+
+		if !f.supported() {
+			report(f, LintWarning, "unsupported type '%s', no flag is generated", f.typ)
+		}
+		if _, err := f.defaultValue(); err != nil && f.parseable() {
+			report(f, LintError, "%s", err)
+		}
+
+		if len(f.short) > 1 || (f.short != "" && f.short[0] > unicode.MaxASCII) {
+			report(f, LintError, "short flag '%s' must be a single ASCII character", f.short)
+		}
+
+		if f.env != "" && tagValue(f.tag, "env", "e") == "" && !isValidEnv(f.env) {
+			report(f, LintError, "environment variable '%s' is not a valid POSIX name", f.env)
+		}
+
+		if _, ok := f.tag.Lookup("deprecated"); ok && deprecationMessage(f.tag) == "" {
+			report(f, LintError, "deprecated requires a message")
+		}
+
+		duplicate(f, keys, "key", f.key)
+		duplicate(f, envs, "environment variable", f.env)
+		for _, env := range f.aliasEnvs {
+			duplicate(f, envs, "alias environment variable", env)
+		}
+		if f.supported() {
+			duplicate(f, longs, "flag", f.long)
+			duplicate(f, shorts, "short flag", f.short)
+			for _, long := range f.aliasLongs {
+				duplicate(f, longs, "alias flag", long)
+			}
+		}
+
+		if err := f.checkConstraints(); err != nil {
+			report(f, LintError, "%s", err)
+		}
+
+		if err := f.checkSecretFile(); err != nil {
+			report(f, LintError, "%s", err)
+		}
+
+		if err := f.checkRequiredEnv(); err != nil {
+			report(f, LintError, "%s", err)
+		}
+
+		if err := f.checkDecrypt(); err != nil {
+			report(f, LintError, "%s", err)
+		}
+
+		if err := f.checkUnit(); err != nil {
+			report(f, LintError, "%s", err)
+		}
+
+		if err := f.checkEncoding(); err != nil {
+			report(f, LintError, "%s", err)
+		}
+
+		if f.typ == timeType {
+			if err := checkLayouts(f.layouts()); err != nil {
+				report(f, LintError, "%s", err)
+			}
+		}
+
+		if f.isRequired() && f.def != "" {
+			report(f, LintWarning, "required field has a default, so it is always set")
+		}
+
+		// The value of a flag is visible to other users in the process
+		// list, unlike that of an environment variable or a secret file
+		if isTrue(f.tag.Get("secret")) && !f.isSecretFile() && f.long != "" && f.supported() {
+			report(f, LintWarning, "secret field is exposed as the flag '--%s', whose value is visible in the process list, consider secretFile", f.long)
+		}
+
+		if f.tag.Get("exclusiveBool") != "" && f.typ.Kind() != reflect.Bool {
+			report(f, LintError, "exclusiveBool is only valid for boolean fields")
+		}
+
+		idx, err := f.positionalIndex()
+		if err != nil {
+			report(f, LintError, "invalid positional index '%s'", f.tag.Get("positional"))
+		} else if other, ok := positionals[idx]; ok && idx >= 0 {
+			report(f, LintError, "positional argument %d is also bound to field '%s'", idx, other)
+		} else if idx >= 0 {
+			positionals[idx] = f.name
+		}
+	}
+	return issues, nil
+}
+
+// knownTagName returns true if the name is a structure tag used by venom
+// or one commonly used by other packages
+func knownTagName(name string) bool {
+	if name == "ignored" {
+		return true
+	}
+	for _, known := range append(tagNames, foreignTagNames...) {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// tagKeys returns the keys of the structure tag, following the
+// conventional format parsed by reflect.StructTag.Get
+func tagKeys(tag reflect.StructTag) []string {
+	var keys []string
+	for tag != "" {
+		tag = reflect.StructTag(strings.TrimLeft(string(tag), " "))
+		i := strings.Index(string(tag), ":\"")
+		if i <= 0 {
+			break
+		}
+		keys = append(keys, string(tag[:i]))
+		tag = tag[i+2:]
+
+		// Skip the quoted value, honoring escaped quotes
+		for i = 0; i < len(tag) && tag[i] != '"'; i++ {
+			if tag[i] == '\\' {
+				i++
+			}
+		}
+		if i >= len(tag) {
+			break
+		}
+		tag = tag[i+1:]
+	}
+	return keys
+}
@@ -0,0 +1,160 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"strings"
+	"testing"
+)
+
+// lintMessages returns the messages of the issues reported for the field
+func lintMessages(issues []LintIssue, field string) []string {
+	var messages []string
+	for _, issue := range issues {
+		if issue.Field == field {
+			messages = append(messages, issue.Message)
+		}
+	}
+	return messages
+}
+
+func TestLintSecretFlag(t *testing.T) {
+	var c struct {
+		Password string `secret:"true"`
+		Token    string `secret:"true" secretFile:"true"`
+		Key      string `secret:"false"`
+	}
+
+	issues, err := Lint(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := lintMessages(issues, "Password")
+	if len(messages) != 1 || !strings.Contains(messages[0], "exposed as the flag '--password'") {
+		t.Errorf("expected the secret flag to be reported for Password, got %v", messages)
+	}
+	for _, issue := range issues {
+		if issue.Field == "Password" && issue.Severity != LintWarning {
+			t.Errorf("expected a warning, got %s", issue.Severity)
+		}
+	}
+	if messages := lintMessages(issues, "Token"); len(messages) != 0 {
+		t.Errorf("expected no issues for a secret file, got %v", messages)
+	}
+	if messages := lintMessages(issues, "Key"); len(messages) != 0 {
+		t.Errorf("expected no issues for a field that is not secret, got %v", messages)
+	}
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     interface{}
+		field    string
+		severity Severity
+		message  string
+	}{
+		{
+			name: "unknown tag",
+			spec: &struct {
+				Port int `defualt:"80"`
+			}{},
+			field: "Port", severity: LintWarning, message: "unknown tag 'defualt'",
+		},
+		{
+			name: "invalid default",
+			spec: &struct {
+				Port int `default:"http"`
+			}{},
+			field: "Port", severity: LintError, message: "http",
+		},
+		{
+			name: "long short flag",
+			spec: &struct {
+				Port int `short:"pp"`
+			}{},
+			field: "Port", severity: LintError, message: "short flag 'pp' must be a single ASCII character",
+		},
+		{
+			name: "duplicate flag",
+			spec: &struct {
+				Port  int
+				Other int `long:"port"`
+			}{},
+			field: "Other", severity: LintError, message: "flag 'port' is also used by field 'Port'",
+		},
+		{
+			name: "duplicate env",
+			spec: &struct {
+				Port  int `env:"PORT"`
+				Other int `env:"port"`
+			}{},
+			field: "Other", severity: LintError, message: "environment variable 'port' is also used by field 'Port'",
+		},
+		{
+			name: "unsupported type",
+			spec: &struct {
+				Handler func()
+			}{},
+			field: "Handler", severity: LintWarning, message: "unsupported type 'func()'",
+		},
+		{
+			name: "duplicate positional",
+			spec: &struct {
+				A string `positional:"0"`
+				B string `positional:"0"`
+			}{},
+			field: "B", severity: LintError, message: "positional argument 0 is also bound to field 'A'",
+		},
+		{
+			name: "required with default",
+			spec: &struct {
+				Host string `required:"true" default:"localhost"`
+			}{},
+			field: "Host", severity: LintWarning, message: "required field has a default",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issues, err := Lint(test.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, issue := range issues {
+				if issue.Field == test.field && issue.Severity == test.severity && strings.Contains(issue.Message, test.message) {
+					return
+				}
+			}
+			t.Errorf("expected a %s for '%s' containing '%s', got %v", test.severity, test.field, test.message, issues)
+		})
+	}
+}
+
+func TestLintClean(t *testing.T) {
+	var c struct {
+		Host    string `default:"localhost" help:"host to connect to" json:"host"`
+		Port    int    `default:"80" short:"p" yaml:"port"`
+		Skipped func() `ignored:"true"`
+	}
+	issues, err := Lint(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+}
@@ -0,0 +1,100 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// manEscaper escapes the characters that troff would otherwise interpret
+var manEscaper = strings.NewReplacer(`\`, `\e`, "-", `\-`)
+
+// GenerateManPage returns a troff formatted man page, in the given section,
+// e.g. 1 for user commands or 8 for administration commands, whose OPTIONS
+// section describes each flag generated for the configSpecification, in
+// declaration order, with its help, environment variable, and default.
+// Hidden and deprecated flags are omitted and the defaults of secrets are
+// redacted, as in the usage.
+func GenerateManPage(configSpecification interface{}, prefix string, options ProcessingOptions, section int) (string, error) {
+	if section < 1 || section > 9 {
+		return "", fmt.Errorf("invalid section '%d', must be between 1 and 9", section)
+	}
+
+	name := path.Base(os.Args[0])
+	flagSet := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	fields, err := addConfiguration(viper.New(), flagSet, configSpecification, prefix, options)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH \"%s\" \"%d\"\n", manEscape(strings.ToUpper(name)), section)
+	fmt.Fprintf(&b, ".SH OPTIONS\n")
+	for _, f := range fields {
+		if f.long == "" {
+			continue
+		}
+		flag := flagSet.Lookup(f.long)
+		if flag == nil || flag.Hidden || flag.Deprecated != "" {
+			continue
+		}
+
+		fmt.Fprintf(&b, ".TP\n")
+		if flag.Shorthand != "" {
+			fmt.Fprintf(&b, "\\fB\\-%s\\fR, ", manEscape(flag.Shorthand))
+		}
+		fmt.Fprintf(&b, "\\fB\\-\\-%s\\fR", manEscape(flag.Name))
+		varname, usage := pflag.UnquoteUsage(flag)
+		if varname != "" {
+			fmt.Fprintf(&b, "=\\fI%s\\fR", manEscape(varname))
+		}
+		fmt.Fprintf(&b, "\n")
+		if usage != "" {
+			fmt.Fprintf(&b, "%s\n", manEscapeLine(usage))
+		}
+		if f.env != "" {
+			fmt.Fprintf(&b, ".br\nEnvironment: \\fB%s\\fR\n", manEscape(f.env))
+		}
+		if f.def != "" {
+			fmt.Fprintf(&b, ".br\nDefault: %s\n", manEscape(flag.DefValue))
+		}
+	}
+	return b.String(), nil
+}
+
+// manEscape escapes the characters of text that troff would otherwise
+// interpret
+func manEscape(text string) string {
+	return manEscaper.Replace(text)
+}
+
+// manEscapeLine escapes text that is written as one or more lines, each of
+// which troff would interpret as a request if it started with `.` or `'`
+func manEscapeLine(text string) string {
+	lines := strings.Split(manEscape(text), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
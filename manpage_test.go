@@ -0,0 +1,82 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerateManPage(t *testing.T) {
+	defer func(args []string) { os.Args = args }(os.Args)
+	os.Args = []string{"/usr/bin/my-app"}
+
+	var c struct {
+		Host     string `short:"H" default:"localhost" help:"the host to\n.connect to"`
+		Password string `default:"hunter2" secret:"true" help:"the password"`
+		Debug    bool   `hidden:"true"`
+		Old      int    `deprecated:"use --host"`
+		Path     string `help:"a path, e.g. C:\\app"`
+	}
+	page, err := GenerateManPage(&c, "APP", DefaultOptions, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `.TH "MY\-APP" "1"
+.SH OPTIONS
+.TP
+\fB\-H\fR, \fB\-\-host\fR=\fIstring\fR
+the host to
+\&.connect to
+.br
+Environment: \fBAPP_HOST\fR
+.br
+Default: localhost
+.TP
+\fB\-\-password\fR=\fIstring\fR
+the password
+.br
+Environment: \fBAPP_PASSWORD\fR
+.br
+Default: ****
+.TP
+\fB\-\-path\fR=\fIstring\fR
+a path, e.g. C:\eapp
+.br
+Environment: \fBAPP_PATH\fR
+`
+	if page != want {
+		t.Errorf("expected the man page:\n%s\ngot:\n%s", want, page)
+	}
+}
+
+func TestGenerateManPageSection(t *testing.T) {
+	var c struct {
+		Host string
+	}
+	for _, section := range []int{0, 10} {
+		if _, err := GenerateManPage(&c, "", DefaultOptions, section); err == nil || !strings.Contains(err.Error(), "invalid section") {
+			t.Errorf("expected an error for section %d, got '%v'", section, err)
+		}
+	}
+	page, err := GenerateManPage(&c, "", DefaultOptions, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(strings.SplitN(page, "\n", 2)[0], `"8"`) {
+		t.Errorf("expected the title to name section 8, got '%s'", page)
+	}
+}
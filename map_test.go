@@ -0,0 +1,133 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestMapsFromEnv(t *testing.T) {
+	type spec struct {
+		Labels map[string]string
+		Limits map[string]string `pairSeparator:";" kvSeparator:":"`
+	}
+	tests := []struct {
+		name string
+		env  map[string]string
+		want spec
+	}{
+		{
+			name: "unset",
+		},
+		{
+			name: "env",
+			env:  map[string]string{"MAP_LABELS": "a=1,b=2", "MAP_LIMITS": "cpu:2;mem:512"},
+			want: spec{
+				Labels: map[string]string{"a": "1", "b": "2"},
+				Limits: map[string]string{"cpu": "2", "mem": "512"},
+			},
+		},
+		{
+			name: "separator in value",
+			env:  map[string]string{"MAP_LABELS": "expr=x=y"},
+			want: spec{Labels: map[string]string{"expr": "x=y"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+			var c spec
+			p, err := New(&c, WithDefault, WithPrefix("MAP"), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			// Maps are compared as printed, so that a nil map is equal to
+			// an empty one
+			if fmt.Sprint(c) != fmt.Sprint(test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, c)
+			}
+		})
+	}
+}
+
+func TestParseMapErrors(t *testing.T) {
+	var c struct {
+		Labels map[string]string
+	}
+	os.Setenv("MAP_LABELS", "a=1,b")
+	defer os.Unsetenv("MAP_LABELS")
+	p, err := New(&c, WithDefault, WithPrefix("MAP"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err == nil {
+		t.Error("expected an error for an entry without a separator")
+	}
+}
+
+func TestMapOfLists(t *testing.T) {
+	type spec struct {
+		Headers map[string][]string `default:"accept=text/plain"`
+		Routes  map[string][]string `pairSeparator:"|"`
+	}
+	os.Setenv("MAP_ROUTES", "a=x,y|b=z")
+	defer os.Unsetenv("MAP_ROUTES")
+
+	var c spec
+	p, err := New(&c, WithDefault, WithPrefix("MAP"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--headers", "accept=text/html,application/json", "--headers", "x=1", "--headers", "x=2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{"accept": {"text/html", "application/json"}, "x": {"1", "2"}}
+	if fmt.Sprint(c.Headers) != fmt.Sprint(want) {
+		t.Errorf("expected Headers to be %v, got %v", want, c.Headers)
+	}
+	if routes := map[string][]string{"a": {"x", "y"}, "b": {"z"}}; fmt.Sprint(c.Routes) != fmt.Sprint(routes) {
+		t.Errorf("expected Routes to be %v, got %v", routes, c.Routes)
+	}
+}
+
+func TestMapOfListsDefault(t *testing.T) {
+	var c struct {
+		Headers map[string][]string `default:"accept=text/plain,text/html;x=1"`
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "map[accept:[text/plain text/html] x:[1]]"; fmt.Sprint(c.Headers) != want {
+		t.Errorf("expected Headers to be %s, got %v", want, c.Headers)
+	}
+}
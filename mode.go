@@ -0,0 +1,110 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationMode indicates when the validations that can be performed
+// without the resolved values are run
+type ValidationMode int
+
+// Defines when the validations that do not require the resolved values are
+// run
+const (
+	// ValidateOnApply the defaults, like any other value, are validated
+	// against the `min`, `max`, `choices`, and `validate` tags when the
+	// configuration is applied or validated
+	ValidateOnApply ValidationMode = iota
+
+	// ValidateOnBuild the defaults are also validated, and the functions
+	// referenced by the `validate`, `decrypt`, and `derive` tags are checked
+	// to be registered, when the configuration is bound, before any value
+	// is resolved
+	ValidateOnBuild
+)
+
+// String returns the name of the validation mode
+func (m ValidationMode) String() string {
+	switch m {
+	case ValidateOnApply:
+		return "apply"
+	case ValidateOnBuild:
+		return "build"
+	}
+	return fmt.Sprintf("ValidationMode(%d)", int(m))
+}
+
+// WithValidationMode specifies when the validations that do not require the
+// resolved values are run, see ValidationMode
+func WithValidationMode(mode ValidationMode) Option {
+	return optionFunc(func(p *Processor) {
+		p.options.ValidationMode = mode
+	})
+}
+
+// validateOnBuild validates the default of each field against its `min`,
+// `max`, `choices`, and `validate` tags, and checks that the functions
+// referenced by its `decrypt` and `derive` tags are registered, returning
+// the errors for all the fields
+func validateOnBuild(fields []*field) error {
+	var errs Errors
+	for _, f := range fields {
+		if name := f.tag.Get("decrypt"); name != "" {
+			if _, ok := lookupDecryptor(name); !ok {
+				errs = append(errs, configErrorf(f, "decrypt", "unknown decryptor '%s'", name))
+			}
+		}
+		if name := f.tag.Get("derive"); name != "" {
+			if _, ok := lookupDerive(name); !ok {
+				errs = append(errs, configErrorf(f, "derive", "unknown derive function '%s'", name))
+			}
+		}
+
+		// Without a default, the validators are only checked to be
+		// registered, which validateField otherwise reports
+		if f.isRaw() || !f.parseable() || f.def == "" {
+			errs = append(errs, checkValidators(f)...)
+			continue
+		}
+		value, err := f.decode(f.def)
+		if err != nil {
+			errs = append(errs, configError(f, "default", err))
+			continue
+		}
+		errs = append(errs, validateConstraints(f, value)...)
+		errs = append(errs, validateField(f, value)...)
+	}
+	return errs.errorOrNil()
+}
+
+// checkValidators returns an error for each validator referenced by the
+// `validate` tag of the field that is not registered
+func checkValidators(f *field) Errors {
+	var errs Errors
+	for _, ref := range strings.Split(f.tag.Get("validate"), ",") {
+		name := strings.TrimPrefix(strings.TrimSpace(ref), "@")
+		if name == "" {
+			continue
+		}
+		if _, ok := lookupValidator(name); !ok {
+			errs = append(errs, configErrorf(f, "validate", "unknown validator '%s'", name))
+		}
+	}
+	return errs
+}
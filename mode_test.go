@@ -0,0 +1,112 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+type modeSpec struct {
+	Port     int    `default:"70000" max:"65535"`
+	Level    string `default:"trace" choices:"debug,info"`
+	Count    int    `default:"3" validate:"@test-even"`
+	Secret   string `secret:"true" decrypt:"test-missing"`
+	Metrics  string `deriveFrom:"Level" derive:"test-missing"`
+	Checked  int    `validate:"@test-missing"`
+	Accepted int    `default:"8" validate:"@test-even" max:"10"`
+}
+
+func TestValidateOnBuild(t *testing.T) {
+	var c modeSpec
+	_, err := New(&c, WithDefault, WithValidationMode(ValidateOnBuild), WithViper(viper.New()))
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("expected Errors, got '%v'", err)
+	}
+	var got []string
+	for _, e := range errs {
+		var cerr *ConfigError
+		if !errors.As(e, &cerr) {
+			t.Fatalf("expected a ConfigError, got '%v'", e)
+		}
+		got = append(got, cerr.Field+":"+cerr.Tag)
+	}
+	want := []string{"Port:max", "Level:choices", "Count:validate", "Secret:decrypt", "Metrics:derive", "Checked:validate"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("expected the errors %v, got %v", want, got)
+	}
+}
+
+func TestValidateOnApply(t *testing.T) {
+	var c struct {
+		Port  int    `default:"70000" max:"65535"`
+		Level string `default:"trace" choices:"debug,info"`
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatalf("expected the defaults not to be validated when binding, got '%v'", err)
+	}
+	if err := p.Parse([]string{"--port=80", "--level=info"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Errorf("expected the overridden defaults not to be validated, got '%v'", err)
+	}
+}
+
+func TestValidationModeString(t *testing.T) {
+	for mode, want := range map[ValidationMode]string{
+		ValidateOnApply:   "apply",
+		ValidateOnBuild:   "build",
+		ValidationMode(5): "ValidationMode(5)",
+	} {
+		if got := mode.String(); got != want {
+			t.Errorf("expected '%s', got '%s'", want, got)
+		}
+	}
+}
+
+func TestDebugWrittenToStderr(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	var c struct {
+		Host string
+	}
+	_, err = New(&c, WithDefault|WithDebug, WithViper(viper.New()))
+	os.Stderr = stderr
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "Processing field 'Host'") {
+		t.Errorf("expected the debug messages on stderr, got '%s'", out)
+	}
+}
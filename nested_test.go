@@ -0,0 +1,138 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestNestedKeyDelimiter(t *testing.T) {
+	type spec struct {
+		Server struct {
+			Listen struct {
+				Port int `default:"80"`
+			}
+			Name string `default:"web"`
+		}
+	}
+
+	tests := []struct {
+		delimiter string
+		key       string
+	}{
+		{delimiter: ".", key: "server.listen.port"},
+		{delimiter: "::", key: "server::listen::port"},
+	}
+	for _, test := range tests {
+		var c spec
+		v := viper.NewWithOptions(viper.KeyDelimiter(test.delimiter))
+		p, err := New(&c, WithDefault, WithKeyDelimiter(test.delimiter), WithViper(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Parse([]string{"--server-listen-port=8080"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := v.GetInt(test.key); got != 8080 {
+			t.Errorf("expected '%s' to be 8080, got %d", test.key, got)
+		}
+		if err := p.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		if c.Server.Listen.Port != 8080 || c.Server.Name != "web" {
+			t.Errorf("expected the nested members to be set, got %+v", c.Server)
+		}
+	}
+}
+
+func TestDeeplyNestedDefaults(t *testing.T) {
+	type tls struct {
+		Timeout time.Duration `default:"90s"`
+		Verify  bool          `default:"true"`
+	}
+	type server struct {
+		Host string `default:"localhost"`
+		TLS  tls
+	}
+	var c struct {
+		Server server
+	}
+
+	v := viper.New()
+	p, err := New(&c, WithDefault, WithViper(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := v.Get("Server.TLS.Timeout"); got != 90*time.Second {
+		t.Errorf("expected 'Server.TLS.Timeout' to be a duration of 90s, got '%v' (%T)", got, got)
+	}
+	if got := v.Get("Server.TLS.Verify"); got != true {
+		t.Errorf("expected 'Server.TLS.Verify' to be true, got '%v' (%T)", got, got)
+	}
+	if got := v.GetString("Server.Host"); got != "localhost" {
+		t.Errorf("expected 'Server.Host' to be 'localhost', got '%s'", got)
+	}
+	if p.FlagSet().Lookup("server-tls-timeout") == nil {
+		t.Error("expected the flag '--server-tls-timeout' to be defined")
+	}
+
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.TLS.Timeout != 90*time.Second || !c.Server.TLS.Verify {
+		t.Errorf("expected the nested defaults to be populated, got %+v", c.Server.TLS)
+	}
+}
+
+func TestNestedPrefixTag(t *testing.T) {
+	type database struct {
+		Host string `default:"localhost"`
+	}
+	for _, test := range []struct {
+		name string
+		spec interface{}
+	}{
+		{"name", &struct {
+			Primary database `name:"DB"`
+		}{}},
+		{"prefix", &struct {
+			Primary database `prefix:"DB"`
+		}{}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			os.Setenv("APP_DB_HOST", "db.example.com")
+			defer os.Unsetenv("APP_DB_HOST")
+
+			v := viper.New()
+			flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			if err := AddConfigurationTo(v, flagSet, test.spec, "APP", DefaultOptions, nil); err != nil {
+				t.Fatal(err)
+			}
+			if flagSet.Lookup("db-host") == nil {
+				t.Error("expected the flag '--db-host' to be defined")
+			}
+			if got := v.GetString("DB.Host"); got != "db.example.com" {
+				t.Errorf("expected 'DB.Host' to be read from 'APP_DB_HOST', got '%s'", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+type networkSpec struct {
+	Listen   net.IP  `default:"0.0.0.0"`
+	Endpoint url.URL `default:"https://example.com/api"`
+}
+
+func TestNetworkDefaults(t *testing.T) {
+	var c networkSpec
+	p, err := New(&c, WithDefault, WithPrefix("NET"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Listen.Equal(net.IPv4zero) {
+		t.Errorf("expected Listen to be '0.0.0.0', got '%s'", c.Listen)
+	}
+	if got := c.Endpoint.String(); got != "https://example.com/api" {
+		t.Errorf("expected Endpoint to be 'https://example.com/api', got '%s'", got)
+	}
+
+	for name, want := range map[string]string{"listen": "0.0.0.0", "endpoint": "https://example.com/api"} {
+		flag := p.FlagSet().Lookup(name)
+		if flag == nil {
+			t.Fatalf("expected the flag '--%s' to be defined", name)
+		}
+		if flag.DefValue != want {
+			t.Errorf("expected the default of '--%s' to be '%s', got '%s'", name, want, flag.DefValue)
+		}
+	}
+}
+
+func TestNetworkFlags(t *testing.T) {
+	var c networkSpec
+	p, err := New(&c, WithDefault, WithPrefix("NET"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--listen=10.1.2.3", "--endpoint=http://localhost:8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Listen.Equal(net.ParseIP("10.1.2.3")) {
+		t.Errorf("expected Listen to be '10.1.2.3', got '%s'", c.Listen)
+	}
+	if c.Endpoint.Host != "localhost:8080" {
+		t.Errorf("expected the Endpoint host to be 'localhost:8080', got '%s'", c.Endpoint.Host)
+	}
+}
+
+func TestNetworkInvalidValues(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		var c struct {
+			Listen net.IP `default:"not-an-ip"`
+		}
+		_, err := New(&c, WithDefault, WithViper(viper.New()))
+		if err == nil {
+			t.Fatal("expected an error for an invalid IP default")
+		}
+		if !strings.Contains(err.Error(), "not-an-ip") {
+			t.Errorf("expected the error to name the default, got '%s'", err)
+		}
+	})
+	t.Run("flag", func(t *testing.T) {
+		var c networkSpec
+		p, err := New(&c, WithDefault, WithViper(viper.New()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.FlagSet().SetOutput(&strings.Builder{})
+		if err := p.Parse([]string{"--listen=300.1.1.1"}); err == nil {
+			t.Error("expected an error parsing an invalid IP flag")
+		}
+	})
+}
+
+func TestIPMask(t *testing.T) {
+	tests := []struct {
+		def   string
+		want  string
+		valid bool
+	}{
+		{def: "255.255.255.0", want: "255.255.255.0", valid: true},
+		{def: "ffff0000", want: "255.255.0.0", valid: true},
+		{def: "/24", want: "255.255.255.0", valid: true},
+		{def: "/0", want: "0.0.0.0", valid: true},
+		{def: "/33"},
+		{def: "255.0.255.0"},
+		{def: "mask"},
+	}
+	for _, test := range tests {
+		t.Run(test.def, func(t *testing.T) {
+			var c struct {
+				Mask net.IPMask
+			}
+			v := viper.New()
+			flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			tag := reflect.StructTag(`default:"` + test.def + `"`)
+			spec := reflect.New(reflect.StructOf([]reflect.StructField{{Name: "Mask", Type: reflect.TypeOf(c.Mask), Tag: tag}}))
+			err := AddConfigurationTo(v, flagSet, spec.Interface(), "", DefaultOptions, nil)
+			if !test.valid {
+				if err == nil {
+					t.Fatalf("expected an error for the mask default '%s'", test.def)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if def := flagSet.Lookup("mask").DefValue; def != test.def {
+				t.Errorf("expected the flag default to be shown as '%s', got '%s'", test.def, def)
+			}
+			if err := PopulateFrom(v, &c, "", DefaultOptions); err != nil {
+				t.Fatal(err)
+			}
+			if got := formatIPMask(c.Mask); got != test.want {
+				t.Errorf("expected the mask to be '%s', got '%s'", test.want, got)
+			}
+		})
+	}
+}
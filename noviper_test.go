@@ -0,0 +1,72 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestWithoutViper(t *testing.T) {
+	os.Setenv("APP_PORT", "9090")
+	defer os.Unsetenv("APP_PORT")
+
+	var c struct {
+		Host string `default:"localhost"`
+		Port int    `default:"80"`
+	}
+	options := DefaultOptions
+	options.Flags |= WithoutViper
+	v := viper.New()
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := AddConfigurationTo(v, flagSet, &c, "APP", options, nil); err != nil {
+		t.Fatal(err)
+	}
+	if keys := v.AllKeys(); len(keys) != 0 {
+		t.Errorf("expected nothing to be bound to viper, got %v", keys)
+	}
+	for name, want := range map[string]string{"host": "localhost", "port": "80"} {
+		flag := flagSet.Lookup(name)
+		if flag == nil {
+			t.Fatalf("expected the flag '--%s' to be registered", name)
+		}
+		if flag.DefValue != want {
+			t.Errorf("expected the default of '--%s' to be '%s', got '%s'", name, want, flag.DefValue)
+		}
+	}
+	if err := flagSet.Parse([]string{"--port=8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if port, _ := flagSet.GetInt("port"); port != 8080 {
+		t.Errorf("expected the flag to be parsed, got %d", port)
+	}
+	if v.IsSet("port") {
+		t.Error("expected the flag not to be bound to viper")
+	}
+}
+
+func TestWithoutViperNew(t *testing.T) {
+	var c struct {
+		Port int
+	}
+	_, err := New(&c, WithDefault|WithoutViper, WithViper(viper.New()))
+	if err == nil || !strings.Contains(err.Error(), "WithoutViper is not supported by New") {
+		t.Errorf("expected an error for WithoutViper, got '%v'", err)
+	}
+}
@@ -0,0 +1,46 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"reflect"
+	"runtime"
+)
+
+// goos the operating system whose default is used, which is a variable so
+// that the selection can be exercised for other operating systems
+var goos = runtime.GOOS
+
+// osDefaultTags the tags that specify the default of a field on each
+// operating system, keyed by GOOS
+var osDefaultTags = map[string]string{
+	"linux":   "defaultLinux",
+	"darwin":  "defaultDarwin",
+	"windows": "defaultWindows",
+}
+
+// osDefault returns the default of a field, which is the value of the tag
+// for the current operating system, e.g. `defaultDarwin`, if set, with a
+// leading `~` expanded to the home directory, falling back to the
+// `default` tag
+func osDefault(tag reflect.StructTag) string {
+	if name, ok := osDefaultTags[goos]; ok {
+		if def, ok := tag.Lookup(name); ok {
+			return expandHome(def)
+		}
+	}
+	return tagValue(tag, "default", "d")
+}
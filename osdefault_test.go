@@ -0,0 +1,61 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestOSDefault(t *testing.T) {
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", "/home/tester")
+	defer os.Setenv("HOME", home)
+	defer func(previous string) { goos = previous }(goos)
+
+	tag := reflect.StructTag(`default:"/etc/app" defaultDarwin:"~/Library/app" defaultWindows:"C:\\app"`)
+	for _, test := range []struct {
+		goos string
+		want string
+	}{
+		{"linux", "/etc/app"},
+		{"darwin", "/home/tester/Library/app"},
+		{"windows", `C:\app`},
+		{"plan9", "/etc/app"},
+	} {
+		goos = test.goos
+		if got := osDefault(tag); got != test.want {
+			t.Errorf("%s: expected the default '%s', got '%s'", test.goos, test.want, got)
+		}
+	}
+
+	goos = "linux"
+	var c struct {
+		Dir string `default:"/etc/app" defaultLinux:"~/.config/app"`
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Dir != "/home/tester/.config/app" {
+		t.Errorf("expected Dir to be '/home/tester/.config/app', got '%s'", c.Dir)
+	}
+}
@@ -0,0 +1,50 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// isPath returns true if the field is a string tagged as a path, whose
+// leading `~` is expanded when WithHomeExpansion is set
+func (f *field) isPath() bool {
+	return isTrue(f.tag.Get("path"))
+}
+
+// expandsHome returns true if a leading `~` in the values of the field is
+// expanded to the home directory
+func (f *field) expandsHome(options ProcessingOptions) bool {
+	return options.Flags&WithHomeExpansion != 0 && f.isPath() && f.typ.Kind() == reflect.String
+}
+
+// expandHome replaces a leading `~`, when alone or followed by a path
+// separator, with the current user's home directory. Another user's home
+// directory, e.g. `~alice/`, is not expanded, nor is the path if the home
+// directory cannot be determined.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return home + path[1:]
+}
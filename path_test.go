@@ -0,0 +1,91 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestExpandHome(t *testing.T) {
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", "/home/tester")
+	defer os.Setenv("HOME", home)
+
+	for path, want := range map[string]string{
+		"~":          "/home/tester",
+		"~/app":      "/home/tester/app",
+		"~alice/app": "~alice/app",
+		"/opt/~/app": "/opt/~/app",
+		"":           "",
+	} {
+		if got := expandHome(path); got != want {
+			t.Errorf("expected '%s' to expand to '%s', got '%s'", path, want, got)
+		}
+	}
+}
+
+func TestWithHomeExpansion(t *testing.T) {
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", "/home/tester")
+	defer os.Setenv("HOME", home)
+	os.Setenv("PATHS_DATA", "~/data")
+	defer os.Unsetenv("PATHS_DATA")
+
+	type spec struct {
+		Config string `path:"true" default:"~/.app.yaml"`
+		Data   string `path:"true"`
+		Cache  string `path:"true"`
+		Label  string `default:"~/literal"`
+	}
+	for _, test := range []struct {
+		name  string
+		flags Flags
+		want  spec
+	}{
+		{"enabled", WithDefault | WithHomeExpansion, spec{"/home/tester/.app.yaml", "/home/tester/data", "~alice/cache", "~/literal"}},
+		{"disabled", WithDefault, spec{"~/.app.yaml", "~/data", "~alice/cache", "~/literal"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var c spec
+			p, err := New(&c, test.flags, WithPrefix("PATHS"), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse([]string{"--cache=~alice/cache"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if c != test.want {
+				t.Errorf("expected %+v, got %+v", test.want, c)
+			}
+		})
+	}
+}
+
+func TestPathRequiresString(t *testing.T) {
+	var c struct {
+		Dirs []string `path:"true"`
+	}
+	_, err := New(&c, WithDefault|WithHomeExpansion, WithViper(viper.New()))
+	if err == nil || !strings.Contains(err.Error(), "path is only valid for string fields") {
+		t.Errorf("expected an error for path on a list, got '%v'", err)
+	}
+}
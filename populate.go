@@ -0,0 +1,63 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"reflect"
+
+	"github.com/spf13/viper"
+)
+
+// Populate sets each member of the specified configSpecification interface
+// to the value resolved by viper's global instance for the member's key, as
+// derived by AddConfiguration using the same prefix and options, e.g.
+// after the flag set returned by NewConfiguration has been parsed. An error
+// is returned if a resolved value cannot be converted to the type of its
+// member.
+func Populate(configSpecification interface{}, prefix string, options ProcessingOptions) error {
+	return PopulateFrom(viper.GetViper(), configSpecification, prefix, options)
+}
+
+// PopulateFrom is as Populate, but reads the values from the given viper
+// instance, as passed to AddConfigurationTo, rather than viper's global
+// instance.
+func PopulateFrom(v *viper.Viper, configSpecification interface{}, prefix string, options ProcessingOptions) error {
+	fields, err := describeFields(configSpecification, prefix, options)
+	if err != nil {
+		return err
+	}
+
+	p := &Processor{
+		spec:    configSpecification,
+		prefix:  prefix,
+		options: options,
+		viper:   v,
+		fields:  fields,
+	}
+	impls, err := describeImplementations(configSpecification, prefix, options)
+	if err != nil {
+		return err
+	}
+
+	specElem := reflect.ValueOf(configSpecification).Elem()
+	installImplementations(specElem, impls)
+	for _, f := range fields {
+		if err := p.applyField(p.resolve, f, fieldByIndex(specElem, f.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
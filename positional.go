@@ -0,0 +1,112 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// positionalIndex returns the index of the positional argument specified by
+// the field's `positional` tag, or -1 if the field has no such tag
+func (f *field) positionalIndex() (int, error) {
+	tag, ok := f.tag.Lookup("positional")
+	if !ok {
+		return -1, nil
+	}
+	idx, err := strconv.Atoi(tag)
+	if err != nil || idx < 0 {
+		return -1, configErrorf(f, "positional", "invalid positional index '%s', must be a non-negative integer", tag)
+	}
+	return idx, nil
+}
+
+// isRestArgs returns true if the field is bound to the remaining
+// positional arguments by an `args:"rest"` tag
+func (f *field) isRestArgs() bool {
+	return f.tag.Get("args") == "rest"
+}
+
+// validatePositionals checks that each `positional` tag is a valid index,
+// that no two fields are bound to the same positional argument, and that at
+// most one []string field is bound to the remaining arguments
+func validatePositionals(fields []*field) error {
+	bound := map[int]string{}
+	rest := ""
+	for _, f := range fields {
+		if tag, ok := f.tag.Lookup("args"); ok {
+			if tag != "rest" {
+				return configErrorf(f, "args", "invalid args '%s', must be 'rest'", tag)
+			}
+			if f.typ.Kind() != reflect.Slice || f.typ.Elem().Kind() != reflect.String {
+				return configErrorf(f, "args", "args is only valid for []string fields")
+			}
+			if rest != "" {
+				return fmt.Errorf("fields '%s' and '%s' are both bound to the remaining arguments", rest, f.name)
+			}
+			rest = f.name
+		}
+
+		idx, err := f.positionalIndex()
+		if err != nil {
+			return err
+		}
+		if idx < 0 {
+			continue
+		}
+		if other, ok := bound[idx]; ok {
+			return fmt.Errorf("fields '%s' and '%s' are both bound to positional argument %d", other, f.name, idx)
+		}
+		bound[idx] = f.name
+	}
+	return nil
+}
+
+// positional returns the positional argument bound to the field, if the
+// field has a `positional` tag, the argument was provided, and the field's
+// flag was not explicitly set
+func (p *Processor) positional(f *field) (string, bool) {
+	idx, err := f.positionalIndex()
+	if err != nil || idx < 0 || idx >= p.flagSet.NArg() {
+		return "", false
+	}
+	if f.long != "" {
+		if flag := p.flagSet.Lookup(f.long); flag != nil && flag.Changed {
+			return "", false
+		}
+	}
+	return p.flagSet.Arg(idx), true
+}
+
+// restArgs returns the positional arguments that follow those bound to
+// fields by `positional` tags, if there are any, for the field bound to the
+// remaining arguments
+func (p *Processor) restArgs(f *field) ([]string, bool) {
+	if !f.isRestArgs() {
+		return nil, false
+	}
+	first := 0
+	for _, other := range p.fields {
+		if idx, err := other.positionalIndex(); err == nil && idx >= first {
+			first = idx + 1
+		}
+	}
+	if first >= p.flagSet.NArg() {
+		return nil, false
+	}
+	return p.flagSet.Args()[first:], true
+}
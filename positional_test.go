@@ -0,0 +1,160 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+type positionalSpec struct {
+	Source string `positional:"0"`
+	Target string `positional:"1" default:"out"`
+	Count  int    `positional:"2"`
+}
+
+func TestPositional(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want positionalSpec
+	}{
+		{name: "all", args: []string{"in", "dest", "3"}, want: positionalSpec{"in", "dest", 3}},
+		{name: "default", args: []string{"in"}, want: positionalSpec{"in", "out", 0}},
+		{name: "flag wins", args: []string{"--target=flag", "in", "dest"}, want: positionalSpec{"in", "flag", 0}},
+		{name: "arg over env", env: "env", args: []string{"in", "dest"}, want: positionalSpec{"in", "dest", 0}},
+		{name: "env without arg", env: "env", args: []string{"in"}, want: positionalSpec{"in", "env", 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.env != "" {
+				os.Setenv("POS_TARGET", test.env)
+				defer os.Unsetenv("POS_TARGET")
+			}
+			var c positionalSpec
+			p, err := New(&c, WithDefault, WithPrefix("POS"), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if c != test.want {
+				t.Errorf("expected %+v, got %+v", test.want, c)
+			}
+		})
+	}
+}
+
+func TestPositionalErrors(t *testing.T) {
+	tests := []struct {
+		spec interface{}
+		want string
+	}{
+		{spec: &struct {
+			A string `positional:"first"`
+		}{}, want: "invalid positional index 'first'"},
+		{spec: &struct {
+			A string `positional:"-1"`
+		}{}, want: "invalid positional index '-1'"},
+		{spec: &struct {
+			A string `positional:"0"`
+			B string `positional:"0"`
+		}{}, want: "fields 'A' and 'B' are both bound to positional argument 0"},
+	}
+	for _, test := range tests {
+		_, err := New(test.spec, WithDefault, WithViper(viper.New()))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("expected an error containing '%s', got '%v'", test.want, err)
+		}
+	}
+
+	var c positionalSpec
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"in", "dest", "many"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err == nil {
+		t.Error("expected an error converting a positional argument")
+	}
+}
+
+func TestRestArgs(t *testing.T) {
+	type spec struct {
+		Command string   `positional:"0"`
+		Args    []string `args:"rest" default:"status"`
+	}
+	tests := []struct {
+		args    []string
+		command string
+		rest    []string
+	}{
+		{args: []string{"run", "a", "b"}, command: "run", rest: []string{"a", "b"}},
+		{args: []string{"run", "--", "-x"}, command: "run", rest: []string{"-x"}},
+		{args: []string{"run"}, command: "run", rest: []string{"status"}},
+	}
+	for _, test := range tests {
+		var c spec
+		p, err := New(&c, WithDefault, WithViper(viper.New()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Parse(test.args); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		if c.Command != test.command || !reflect.DeepEqual(c.Args, test.rest) {
+			t.Errorf("%v: expected '%s' %v, got '%s' %v", test.args, test.command, test.rest, c.Command, c.Args)
+		}
+	}
+}
+
+func TestRestArgsErrors(t *testing.T) {
+	tests := []struct {
+		spec interface{}
+		want string
+	}{
+		{spec: &struct {
+			Args []string `args:"all"`
+		}{}, want: "invalid args 'all', must be 'rest'"},
+		{spec: &struct {
+			Args []int `args:"rest"`
+		}{}, want: "args is only valid for []string fields"},
+		{spec: &struct {
+			A []string `args:"rest"`
+			B []string `args:"rest"`
+		}{}, want: "fields 'A' and 'B' are both bound to the remaining arguments"},
+	}
+	for _, test := range tests {
+		_, err := New(test.spec, WithDefault, WithViper(viper.New()))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("expected an error containing '%s', got '%v'", test.want, err)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// Source identifies where a configuration value was provided
+type Source int
+
+// Defines the sources from which a configuration value can be provided
+const (
+	// FlagSource a value provided by a command line flag
+	FlagSource Source = iota
+
+	// EnvSource a value provided by an environment variable
+	EnvSource
+
+	// FileSource a value provided by the configuration file read by viper
+	FileSource
+
+	// DefaultSource a value provided by the default tag
+	DefaultSource
+
+	// ArgSource a value provided by a positional argument, which is not
+	// part of a precedence order
+	ArgSource
+)
+
+// String returns the name of the source
+func (s Source) String() string {
+	switch s {
+	case FlagSource:
+		return "flag"
+	case EnvSource:
+		return "env"
+	case FileSource:
+		return "file"
+	case DefaultSource:
+		return "default"
+	case ArgSource:
+		return "arg"
+	}
+	return fmt.Sprintf("Source(%d)", int(s))
+}
+
+// allSources the sources that must be present in a precedence order
+var allSources = []Source{FlagSource, EnvSource, FileSource, DefaultSource}
+
+// WithPrecedence specifies the order, highest first, in which Apply
+// considers the sources of a value when resolving each field, replacing
+// viper's precedence. The order must contain each source exactly once.
+func WithPrecedence(order []Source) Option {
+	return optionFunc(func(p *Processor) {
+		p.precedence = order
+	})
+}
+
+// validatePrecedence checks that the order contains each source exactly once
+func validatePrecedence(order []Source) error {
+	seen := map[Source]bool{}
+	for _, src := range order {
+		if seen[src] {
+			return fmt.Errorf("invalid precedence: source '%s' specified more than once", src)
+		}
+		seen[src] = true
+	}
+	for _, src := range allSources {
+		if !seen[src] {
+			return fmt.Errorf("invalid precedence: source '%s' not specified", src)
+		}
+	}
+	if len(order) != len(allSources) {
+		return fmt.Errorf("invalid precedence: unknown source specified")
+	}
+	return nil
+}
+
+// resolver returns the function used to resolve the value of a field,
+// which follows the processor's precedence order if one was specified. A
+// positional argument bound to the field is used in preference to any
+// source other than an explicitly set flag, while the remaining arguments
+// are used in preference to any other source. A field tagged `presence` is
+// true if its environment variable is set, unless its flag was set.
+func (p *Processor) resolver() (func(*field) (interface{}, error), error) {
+	resolve := p.resolve
+	if p.precedence != nil {
+		file, err := p.fileConfig()
+		if err != nil {
+			return nil, err
+		}
+		resolve = func(f *field) (interface{}, error) {
+			return p.resolveByPrecedence(f, file)
+		}
+	}
+	return func(f *field) (interface{}, error) {
+		if arg, ok := p.positional(f); ok {
+			return arg, nil
+		}
+		if args, ok := p.restArgs(f); ok {
+			return args, nil
+		}
+		if p.present(f) {
+			return true, nil
+		}
+		return resolve(f)
+	}, nil
+}
+
+// present returns true if the field is tagged `presence` and its
+// environment variable is set, to any value, and its flag was not set
+func (p *Processor) present(f *field) bool {
+	if !isTrue(f.tag.Get("presence")) || f.env == "" {
+		return false
+	}
+	if f.long != "" && changedFlag(p.flagSet, f) != nil {
+		return false
+	}
+	_, ok := os.LookupEnv(f.env)
+	return ok
+}
+
+// resolveByPrecedence returns the value of the field from the first source
+// in the processor's precedence order that provides one
+func (p *Processor) resolveByPrecedence(f *field, file *viper.Viper) (interface{}, error) {
+	for _, src := range p.precedence {
+		if value, ok, err := p.lookup(f, src, file); ok || err != nil {
+			return value, err
+		}
+	}
+	return nil, nil
+}
+
+// lookup returns the value of the field provided by the given source and
+// whether the source provides a value
+func (p *Processor) lookup(f *field, src Source, file *viper.Viper) (interface{}, bool, error) {
+	switch src {
+	case FlagSource:
+		if f.long == "" {
+			break
+		}
+		if flag := changedFlag(p.flagSet, f); flag != nil {
+			return flag.Value.String(), true, nil
+		}
+	case EnvSource:
+		if f.env == "" {
+			break
+		}
+		if value, ok := os.LookupEnv(f.env); ok && value != "" {
+			resolved, err := resolveValue(value)
+			return resolved, true, err
+		}
+		if p.emptyEnvIsTrue(f) {
+			return true, true, nil
+		}
+		if value, ok := f.legacyEnv(); ok {
+			transformed, err := f.transformLegacy(value)
+			return transformed, true, err
+		}
+		if value, ok := f.aliasEnv(); ok {
+			resolved, err := resolveValue(value)
+			return resolved, true, err
+		}
+	case FileSource:
+		if file != nil && file.IsSet(f.read) {
+			return file.Get(f.read), true, nil
+		}
+	case DefaultSource:
+		if f.def != "" {
+			value, err := f.parse(f.def)
+			return value, true, err
+		}
+	}
+	return nil, false, nil
+}
+
+// fileConfig returns a viper instance containing only the values from the
+// configuration specified by WithConfigReader and WithConfigFiles or,
+// otherwise, the configuration file used by the processor's viper instance,
+// or nil if there is no configuration. Viper does not provide access to the
+// values from the configuration alone, so the configuration is read again.
+func (p *Processor) fileConfig() (*viper.Viper, error) {
+	file := viper.NewWithOptions(viper.KeyDelimiter(p.options.keyDelimiter()))
+	if p.config != nil || len(p.configFiles) > 0 {
+		if err := p.readConfig(file); err != nil {
+			return nil, err
+		}
+		return file, nil
+	}
+
+	path := p.viper.ConfigFileUsed()
+	if path == "" {
+		return nil, nil
+	}
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Sources returns the source that provided the value of each bound key,
+// following the processor's precedence order if one was specified and
+// viper's precedence otherwise. A key for which no source provides a value
+// is reported as DefaultSource, as it is set to the zero value of its type.
+func (p *Processor) Sources() (map[string]Source, error) {
+	file, err := p.fileConfig()
+	if err != nil {
+		return nil, err
+	}
+	order := p.precedence
+	if order == nil {
+		order = allSources
+	}
+
+	fields := append([]*field{}, p.fields...)
+	for _, b := range p.bound {
+		fields = append(fields, b.field)
+	}
+
+	sources := map[string]Source{}
+	for _, f := range fields {
+		sources[f.key] = p.sourceOf(f, file, order)
+	}
+	return sources, nil
+}
+
+// sourceOf returns the first source in the given order that provides a
+// value for the field
+func (p *Processor) sourceOf(f *field, file *viper.Viper, order []Source) Source {
+	if _, ok := p.positional(f); ok {
+		return ArgSource
+	}
+	if _, ok := p.restArgs(f); ok {
+		return ArgSource
+	}
+	for _, src := range order {
+		if _, ok, _ := p.lookup(f, src, file); ok {
+			return src
+		}
+	}
+	return DefaultSource
+}
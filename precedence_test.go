@@ -0,0 +1,187 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+type precedenceSpec struct {
+	Level string `default:"default"`
+}
+
+// applyWithPrecedence resolves precedenceSpec with each source providing a
+// value named after it, except those omitted
+func applyWithPrecedence(t *testing.T, order []Source, omit ...Source) (string, error) {
+	t.Helper()
+	omitted := map[Source]bool{}
+	for _, src := range omit {
+		omitted[src] = true
+	}
+
+	if !omitted[EnvSource] {
+		os.Setenv("PREC_LEVEL", "env")
+		defer os.Unsetenv("PREC_LEVEL")
+	}
+	v := viper.New()
+	if !omitted[FileSource] {
+		dir, err := ioutil.TempDir("", "venom")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "config.yaml")
+		if err := ioutil.WriteFile(path, []byte("level: file"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := []Option{WithDefault, WithPrefix("PREC"), WithViper(v)}
+	if order != nil {
+		opts = append(opts, WithPrecedence(order))
+	}
+
+	var c precedenceSpec
+	p, err := New(&c, opts...)
+	if err != nil {
+		return "", err
+	}
+	var args []string
+	if !omitted[FlagSource] {
+		args = []string{"--level=flag"}
+	}
+	if err := p.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	err = p.Apply()
+	return c.Level, err
+}
+
+func TestPrecedence(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []Source
+		omit  []Source
+		want  string
+	}{
+		{name: "viper", want: "flag"},
+		{name: "viper without flag", omit: []Source{FlagSource}, want: "env"},
+		{name: "file first", order: []Source{FileSource, FlagSource, EnvSource, DefaultSource}, want: "file"},
+		{name: "env before flag", order: []Source{EnvSource, FlagSource, FileSource, DefaultSource}, want: "env"},
+		{name: "fall through", order: []Source{EnvSource, FileSource, FlagSource, DefaultSource}, omit: []Source{EnvSource, FileSource}, want: "flag"},
+		{name: "default first", order: []Source{DefaultSource, FlagSource, EnvSource, FileSource}, want: "default"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := applyWithPrecedence(t, test.order, test.omit...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("expected Level to be '%s', got '%s'", test.want, got)
+			}
+		})
+	}
+}
+
+func TestInvalidPrecedence(t *testing.T) {
+	tests := []struct {
+		order []Source
+		want  string
+	}{
+		{order: []Source{FlagSource, FlagSource, EnvSource, FileSource, DefaultSource}, want: "source 'flag' specified more than once"},
+		{order: []Source{FlagSource, EnvSource, FileSource}, want: "source 'default' not specified"},
+		{order: []Source{FlagSource, EnvSource, FileSource, DefaultSource, Source(9)}, want: "unknown source specified"},
+	}
+	for _, test := range tests {
+		_, err := applyWithPrecedence(t, test.order)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%v: expected an error containing '%s', got '%v'", test.order, test.want, err)
+		}
+	}
+}
+
+func TestSources(t *testing.T) {
+	var c struct {
+		Flag    string
+		Env     string
+		File    string
+		Default string `default:"x"`
+		Unset   string
+		Arg     string `positional:"0"`
+	}
+	os.Setenv("SRC_ENV", "env")
+	os.Setenv("SRC_FLAG", "env")
+	defer os.Unsetenv("SRC_ENV")
+	defer os.Unsetenv("SRC_FLAG")
+
+	p, err := New(&c, WithDefault, WithPrefix("SRC"), WithViper(viper.New()),
+		WithConfigReader(strings.NewReader("file: file\nenv: file\n"), "yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--flag=flag", "positional"}); err != nil {
+		t.Fatal(err)
+	}
+	sources, err := p.Sources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Source{
+		"flag":    FlagSource,
+		"env":     EnvSource,
+		"file":    FileSource,
+		"default": DefaultSource,
+		"unset":   DefaultSource,
+		"arg":     ArgSource,
+	}
+	for key, src := range want {
+		if sources[key] != src {
+			t.Errorf("expected the source of '%s' to be '%s', got '%s'", key, src, sources[key])
+		}
+	}
+}
+
+func TestSourcesWithPrecedence(t *testing.T) {
+	var c precedenceSpec
+	os.Setenv("PREC_LEVEL", "env")
+	defer os.Unsetenv("PREC_LEVEL")
+	p, err := New(&c, WithDefault, WithPrefix("PREC"), WithViper(viper.New()),
+		WithPrecedence([]Source{FileSource, EnvSource, FlagSource, DefaultSource}),
+		WithConfigReader(strings.NewReader("other: 1\n"), "yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--level=flag"}); err != nil {
+		t.Fatal(err)
+	}
+	sources, err := p.Sources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sources["level"] != EnvSource {
+		t.Errorf("expected the source to follow the precedence, got '%s'", sources["level"])
+	}
+}
@@ -0,0 +1,142 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestProgramPrefix(t *testing.T) {
+	for program, want := range map[string]string{
+		"my-app":           "MY_APP",
+		"/usr/bin/my-app":  "MY_APP",
+		"server.v2":        "SERVER_V2",
+		"./tools/venom":    "VENOM",
+		"Mixed_Case-Tool9": "MIXED_CASE_TOOL9",
+	} {
+		if got := programPrefix(program); got != want {
+			t.Errorf("expected the prefix for '%s' to be '%s', got '%s'", program, want, got)
+		}
+	}
+}
+
+func TestWithProgramPrefix(t *testing.T) {
+	type spec struct {
+		Port int `default:"80"`
+	}
+	options := DefaultOptions
+	options.Flags |= WithProgramPrefix
+
+	os.Setenv("MY_APP_PORT", "8080")
+	defer os.Unsetenv("MY_APP_PORT")
+
+	t.Run("AddConfigurationTo", func(t *testing.T) {
+		var c spec
+		v := viper.New()
+		flagSet := pflag.NewFlagSet("my-app", pflag.ContinueOnError)
+		if err := AddConfigurationTo(v, flagSet, &c, "", options, []string{"/opt/bin/my-app"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := v.GetInt("Port"); got != 8080 {
+			t.Errorf("expected Port to be read from MY_APP_PORT, got %d", got)
+		}
+	})
+	t.Run("New", func(t *testing.T) {
+		var c spec
+		p, err := New(&c, options, WithProgramName("my-app"), WithViper(viper.New()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Parse(nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		if c.Port != 8080 {
+			t.Errorf("expected Port to be read from MY_APP_PORT, got %d", c.Port)
+		}
+	})
+	t.Run("explicit prefix", func(t *testing.T) {
+		var c spec
+		p, err := New(&c, options, WithProgramName("my-app"), WithPrefix("OTHER"), WithViper(viper.New()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Parse(nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		if c.Port != 80 {
+			t.Errorf("expected the explicit prefix to be used, got Port %d", c.Port)
+		}
+	})
+}
+
+func TestPrefixedEnv(t *testing.T) {
+	for _, tc := range []struct {
+		name, prefix string
+		explicit     bool
+		want         string
+	}{
+		{"PORT", "myapp", false, "MYAPP_PORT"},
+		{"PORT", "MYAPP_", false, "MYAPP_PORT"},
+		{"PORT", "", false, "PORT"},
+		{"MYAPP_FOO", "myapp", false, "MYAPP_MYAPP_FOO"},
+		{"MyApp_Port", "MYAPP", true, "MyApp_Port"},
+		{"myapp_port", "MyApp", true, "myapp_port"},
+		{"MYAPPPORT", "myapp", true, "MYAPP_MYAPPPORT"},
+		{"", "myapp", true, ""},
+	} {
+		if got := prefixedEnv(tc.name, tc.prefix, "_", tc.explicit); got != tc.want {
+			t.Errorf("expected '%s' with the prefix '%s' to be '%s', got '%s'", tc.name, tc.prefix, tc.want, got)
+		}
+	}
+}
+
+func TestExplicitEnvWithPrefix(t *testing.T) {
+	os.Setenv("MyApp_Port", "8080")
+	defer os.Unsetenv("MyApp_Port")
+	os.Setenv("MYAPP_HOST", "example.com")
+	defer os.Unsetenv("MYAPP_HOST")
+
+	var c struct {
+		Port int `env:"MyApp_Port"`
+		Host string
+	}
+	p, err := New(&c, WithDefault, WithPrefix("myapp"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Port != 8080 {
+		t.Errorf("expected Port to be read from 'MyApp_Port', got %d", c.Port)
+	}
+	if c.Host != "example.com" {
+		t.Errorf("expected Host to be read from 'MYAPP_HOST', got '%s'", c.Host)
+	}
+}
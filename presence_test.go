@@ -0,0 +1,69 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestPresence(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		env  *string
+		args []string
+		want bool
+	}{
+		{"unset", nil, nil, false},
+		{"empty", stringPtr(""), nil, true},
+		{"false", stringPtr("false"), nil, true},
+		{"flag", stringPtr("true"), []string{"--debug=false"}, false},
+		{"flag only", nil, []string{"--debug"}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.env != nil {
+				os.Setenv("APP_DEBUG", *tc.env)
+				defer os.Unsetenv("APP_DEBUG")
+			}
+			var c struct {
+				Debug bool `presence:"true"`
+			}
+			p, err := New(&c, WithDefault, WithPrefix("APP"), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if c.Debug != tc.want {
+				t.Errorf("expected Debug to be %t, got %t", tc.want, c.Debug)
+			}
+		})
+	}
+}
+
+func TestPresenceRequiresBool(t *testing.T) {
+	var c struct {
+		Debug string `presence:"true"`
+	}
+	if _, err := New(&c, WithDefault, WithViper(viper.New())); err == nil {
+		t.Error("expected an error for presence on a string field")
+	}
+}
@@ -0,0 +1,718 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// ErrVersion returned by Apply when the version flag was set, signaling
+// that the program should exit
+var ErrVersion = errors.New("version requested")
+
+// ErrConfigDump returned by Apply when the configuration dump flag was set,
+// signaling that the program should exit
+var ErrConfigDump = errors.New("configuration dump requested")
+
+// versionFlag the name of the flag registered by WithVersion
+const versionFlag = "version"
+
+// Option customizes the Processor constructed by New
+type Option interface {
+	apply(p *Processor)
+}
+
+// optionFunc adapts a function to the Option interface
+type optionFunc func(p *Processor)
+
+func (f optionFunc) apply(p *Processor) {
+	f(p)
+}
+
+// apply allows processing flags, such as WithDefault and OnlyTagged, to be
+// passed directly to New. The flags are added to any flags already set.
+func (f Flags) apply(p *Processor) {
+	p.options.Flags |= f
+}
+
+// apply allows a complete set of processing options, such as
+// DefaultOptions, to be passed to New, replacing any options already set.
+func (o ProcessingOptions) apply(p *Processor) {
+	p.options = o
+}
+
+// WithEnv specifies that an environment variable should be generated for
+// each field without an `env` tag, i.e. sets GenerateEnv
+func WithEnv() Option {
+	return GenerateEnv
+}
+
+// WithFlag specifies that a flag should be generated for each field without
+// a `long` tag, i.e. sets GenerateFlag
+func WithFlag() Option {
+	return GenerateFlag
+}
+
+// WithPrefix specifies the prefix used for generated environment variables
+func WithPrefix(prefix string) Option {
+	return optionFunc(func(p *Processor) {
+		p.prefix = prefix
+	})
+}
+
+// WithConfigReader specifies a configuration, in the given format, e.g.
+// "yaml", read by viper in place of a configuration file. This is useful
+// for tests or configurations embedded in the program. The reader is read
+// when the processor is constructed and, as with a configuration file,
+// environment variables and flags take precedence over its values.
+func WithConfigReader(r io.Reader, format string) Option {
+	return optionFunc(func(p *Processor) {
+		p.configReader = r
+		p.configType = format
+	})
+}
+
+// WithConfigFiles specifies configuration files that are read, in order,
+// with the values of each file merged over those of the files before it,
+// e.g. a file of defaults followed by an environment specific file
+func WithConfigFiles(paths ...string) Option {
+	return optionFunc(func(p *Processor) {
+		p.configFiles = append(p.configFiles, paths...)
+	})
+}
+
+// readConfig reads the configuration specified by WithConfigReader, if any,
+// and merges the configuration files specified by WithConfigFiles into the
+// given viper instance
+func (p *Processor) readConfig(v *viper.Viper) error {
+	read := false
+	if p.config != nil {
+		v.SetConfigType(p.configType)
+		if err := v.ReadConfig(bytes.NewReader(p.config)); err != nil {
+			return err
+		}
+		read = true
+	}
+	for _, path := range p.configFiles {
+		v.SetConfigFile(path)
+		var err error
+		if read {
+			err = v.MergeInConfig()
+		} else {
+			err = v.ReadInConfig()
+		}
+		if err != nil {
+			return fmt.Errorf("config file '%s': %w", path, err)
+		}
+		read = true
+	}
+	return nil
+}
+
+// WithKeyNamespace specifies a namespace that prefixes every viper key, but
+// not the environment variables or flags, so that multiple configuration
+// specifications can be bound to the same viper instance without their
+// keys colliding
+func WithKeyNamespace(namespace string) Option {
+	return optionFunc(func(p *Processor) {
+		p.options.KeyNamespace = namespace
+	})
+}
+
+// WithLogger specifies the logger that receives warnings generated while
+// processing the configuration specification
+func WithLogger(logger Logger) Option {
+	return optionFunc(func(p *Processor) {
+		p.options.Logger = logger
+	})
+}
+
+// WithKeyDelimiter specifies the delimiter used to join the viper keys of
+// nested fields. This must match the key delimiter of the viper instance,
+// e.g. one created with viper.NewWithOptions(viper.KeyDelimiter("::")).
+func WithKeyDelimiter(delimiter string) Option {
+	return optionFunc(func(p *Processor) {
+		p.options.KeyDelimiter = delimiter
+	})
+}
+
+// WithSeparators specifies the separators used to join the words of
+// generated environment variable and long flag names, by default "_" and
+// "-" respectively, e.g. WithSeparators("__", ".")
+func WithSeparators(env, long string) Option {
+	return optionFunc(func(p *Processor) {
+		p.options.EnvSeparator = env
+		p.options.LongSeparator = long
+	})
+}
+
+// WithKeyReplacer specifies a replacer that is applied to each viper key
+// once it has been generated, e.g. to align the keys with a key replacer
+// already configured on the viper instance
+func WithKeyReplacer(replacer *strings.Replacer) Option {
+	return optionFunc(func(p *Processor) {
+		p.options.KeyReplacer = replacer
+	})
+}
+
+// WithTrace specifies a function that receives an event for each phase of
+// processing each field, i.e. naming, default parsing, and binding. This is
+// intended to help debug complex configuration specifications.
+func WithTrace(fn func(event TraceEvent)) Option {
+	return optionFunc(func(p *Processor) {
+		p.options.Trace = fn
+	})
+}
+
+// WithOnFieldError specifies a callback that is invoked, with the path of
+// struct field names leading to the field, whenever a field fails to be
+// processed or resolved. The callback is invoked before the error is
+// returned, allowing configuration problems to be logged centrally.
+func WithOnFieldError(fn func(fieldPath []string, err error)) Option {
+	return optionFunc(func(p *Processor) {
+		p.options.OnFieldError = fn
+	})
+}
+
+// WithDefaultsFunc specifies a function that is called, with the path of
+// struct field names leading to each field, to provide the field's default
+// as a string, e.g. from a constant. When the function returns true the
+// string overrides the `default` tag and is parsed as the tag would be.
+func WithDefaultsFunc(fn func(fieldPath []string) (string, bool)) Option {
+	return optionFunc(func(p *Processor) {
+		p.options.DefaultsFunc = fn
+	})
+}
+
+// WithViper specifies the viper instance to which the configuration is
+// bound. If not specified the global viper instance is used.
+func WithViper(v *viper.Viper) Option {
+	return optionFunc(func(p *Processor) {
+		p.viper = v
+	})
+}
+
+// WithFlagSet specifies the flag set to which flags are added. If not
+// specified a new flag set is created, named after the running program.
+func WithFlagSet(flagSet *pflag.FlagSet) Option {
+	return optionFunc(func(p *Processor) {
+		p.flagSet = flagSet
+	})
+}
+
+// WithProgramName specifies the program name used in the usage header and
+// as the name of a flag set created by New. If not specified the base name
+// of the running program is used.
+func WithProgramName(name string) Option {
+	return optionFunc(func(p *Processor) {
+		p.name = name
+	})
+}
+
+// WithVersion specifies the program version, which is included in the
+// usage header, and registers a --version flag. When the version flag is
+// set, Apply writes the version and returns ErrVersion, without resolving
+// or validating the configuration, to signal that the program should exit.
+func WithVersion(version string) Option {
+	return optionFunc(func(p *Processor) {
+		p.version = version
+	})
+}
+
+// WithOutput specifies the writer to which the version, configuration dump,
+// and usage are written. By default the version and configuration dump are
+// written to os.Stdout and the usage to os.Stderr.
+func WithOutput(w io.Writer) Option {
+	return optionFunc(func(p *Processor) {
+		p.output = w
+	})
+}
+
+// WithConfigDumpFlag registers a flag with the given name which, when set,
+// causes Apply to write the effective configuration, as YAML, and return
+// ErrConfigDump to signal that the program should exit.
+func WithConfigDumpFlag(name string) Option {
+	return optionFunc(func(p *Processor) {
+		p.dumpFlag = name
+	})
+}
+
+// Processor captures a configuration specification along with the flag set
+// and viper instance to which it has been bound
+type Processor struct {
+	spec       interface{}
+	prefix     string
+	options    ProcessingOptions
+	viper      *viper.Viper
+	flagSet    *pflag.FlagSet
+	fields     []*field
+	name       string
+	version    string
+	dumpFlag   string
+	output     io.Writer
+	precedence []Source
+	raw        map[string]string
+	bound      []binding
+	hooks      []hook
+
+	// providers and derivations set the values of fields that were not
+	// otherwise specified once Apply has resolved the other values
+	providers   []provider
+	derivations []derivation
+
+	// implementations are installed in the interface fields that select
+	// them by Apply, before the values of their fields are set
+	implementations []*implementation
+
+	// added the configuration specifications bound using Add, which are
+	// bound again when Remove rebuilds the flag set, which can only be
+	// rebuilt if the processor created it and so knows its name
+	added       []addition
+	flagSetName string
+
+	// configReader is read, into config, when the processor is
+	// constructed so that the configuration can be read again to
+	// determine its values when a precedence order is specified
+	configReader io.Reader
+	configType   string
+	config       []byte
+	configFiles  []string
+}
+
+// binding is a value bound to the processor, using Bind, outside of the
+// configuration specification
+type binding struct {
+	field  *field
+	target reflect.Value
+}
+
+// New constructs a Processor for the given configSpecification, which must
+// be a pointer to a struct, and binds the configuration to the processor's
+// flag set and viper instance.
+//
+// Processing starts with no flags set and the separators from
+// DefaultOptions, so WithEnv and/or WithFlag (or WithDefault) should
+// typically be specified.
+func New(configSpecification interface{}, opts ...Option) (*Processor, error) {
+	p := &Processor{
+		spec: configSpecification,
+		options: ProcessingOptions{
+			Flags:         None,
+			LongSeparator: DefaultOptions.LongSeparator,
+			EnvSeparator:  DefaultOptions.EnvSeparator,
+		},
+	}
+	for _, opt := range opts {
+		opt.apply(p)
+	}
+	if p.options.Flags&WithoutViper != 0 {
+		return nil, errors.New("WithoutViper is not supported by New, as Apply resolves values using viper")
+	}
+	if p.viper == nil {
+		p.viper = viper.GetViper()
+	}
+	if p.precedence != nil {
+		if err := validatePrecedence(p.precedence); err != nil {
+			return nil, err
+		}
+	}
+	if p.name == "" {
+		p.name = path.Base(os.Args[0])
+	}
+	if p.prefix == "" && p.options.Flags&WithProgramPrefix != 0 {
+		p.prefix = programPrefix(p.name)
+	}
+	if p.flagSet == nil {
+		p.flagSet = pflag.NewFlagSet(p.name, pflag.ContinueOnError)
+		p.flagSetName = p.name
+	}
+	if p.output != nil {
+		p.flagSet.SetOutput(p.output)
+	}
+	if p.flagSet.Usage == nil {
+		p.flagSet.Usage = p.usage
+	}
+
+	fields, err := addConfiguration(p.viper, p.flagSet, p.spec, p.prefix, p.options)
+	if err != nil {
+		return nil, err
+	}
+	p.fields = fields
+
+	// The fields have been traced and logged as they were added, so they
+	// are described again quietly
+	quiet := p.options
+	quiet.Logger = nil
+	quiet.Trace = nil
+	if p.providers, err = describeProviders(p.spec, p.prefix, quiet, fields); err != nil {
+		return nil, err
+	}
+	if p.implementations, err = describeImplementations(p.spec, p.prefix, quiet); err != nil {
+		return nil, err
+	}
+	if p.derivations, err = describeDerivations(fields); err != nil {
+		return nil, err
+	}
+	if err := p.checkReservedFlags(); err != nil {
+		return nil, err
+	}
+
+	if p.configReader != nil {
+		if p.config, err = ioutil.ReadAll(p.configReader); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.readConfig(p.viper); err != nil {
+		return nil, err
+	}
+
+	if p.version != "" {
+		p.flagSet.Bool(versionFlag, false, "display the version and exit")
+	}
+	if p.dumpFlag != "" {
+		p.flagSet.Bool(p.dumpFlag, false, "display the effective configuration and exit")
+	}
+	return p, nil
+}
+
+// resolve returns the value of the field from viper. When the value was
+// provided by the field's environment variable, references of the form
+// `@scheme:ref` are resolved using the registered value resolvers. When the
+// value was provided by the field's legacy environment variable it is also
+// transformed.
+func (p *Processor) resolve(f *field) (interface{}, error) {
+	// Viper treats an empty environment variable as unset, so it is
+	// resolved here rather than by viper
+	if p.emptyEnvIsTrue(f) {
+		return true, nil
+	}
+	raw := p.viper.Get(f.read)
+	if f.env == "" || f.read != f.key {
+		return raw, nil
+	}
+	if s, ok := raw.(string); ok {
+		if env, set := os.LookupEnv(f.env); set && env == s {
+			return resolveValue(s)
+		}
+		if legacy, ok := f.legacyEnv(); ok && legacy == s {
+			return f.transformLegacy(s)
+		}
+		if alias, ok := f.aliasEnv(); ok && alias == s {
+			return resolveValue(s)
+		}
+	}
+	return raw, nil
+}
+
+// checkReservedFlags returns an error if a field claims one of the flags
+// reserved for the processor's meta flags, i.e. -h and --help, unless
+// WithoutAutoHelp is set, --version, when a version was specified, and the
+// configuration dump flag
+func (p *Processor) checkReservedFlags() error {
+	type reserved struct {
+		long, short, purpose string
+	}
+	var meta []reserved
+	if p.options.Flags&WithoutAutoHelp == 0 {
+		meta = append(meta, reserved{"help", "h", "help"})
+	}
+	if p.version != "" {
+		meta = append(meta, reserved{versionFlag, "", "the version"})
+	}
+	if p.dumpFlag != "" {
+		meta = append(meta, reserved{p.dumpFlag, "", "the configuration dump"})
+	}
+
+	for _, f := range p.fields {
+		if f.long == "" || !f.supported() {
+			continue
+		}
+		for _, r := range meta {
+			if f.long == r.long {
+				return configErrorf(f, "long", "flag '--%s' is reserved for %s", r.long, r.purpose)
+			}
+			if r.short != "" && f.short == r.short {
+				return configErrorf(f, "short", "flag '-%s' is reserved for %s", r.short, r.purpose)
+			}
+		}
+	}
+	return nil
+}
+
+// setRaw records the resolved string of a raw field and, when the field is
+// a string, sets it
+func (p *Processor) setRaw(f *field, value reflect.Value, raw string) {
+	if p.raw == nil {
+		p.raw = map[string]string{}
+	}
+	p.raw[f.name] = raw
+	if f.typ.Kind() == reflect.String {
+		value.Set(reflect.ValueOf(raw).Convert(f.typ))
+	}
+}
+
+// RawValue returns the string resolved by Apply for the field with the
+// given name, e.g. `Server.Codec`, tagged `raw:"true"`. The string is not
+// converted to the field's type, so that the program can parse it.
+func (p *Processor) RawValue(name string) (string, bool) {
+	value, ok := p.raw[name]
+	return value, ok
+}
+
+// usage writes the usage header, including the version when specified,
+// followed by the flag defaults
+func (p *Processor) usage() {
+	out := p.output
+	if out == nil {
+		out = os.Stderr
+	}
+	if p.version != "" {
+		fmt.Fprintf(out, "%s version %s\n\n", p.name, p.version)
+	}
+	fmt.Fprintf(out, "Usage of %s:\n", p.name)
+	p.flagSet.PrintDefaults()
+}
+
+// flagRequested returns true if the named boolean flag was registered and
+// set to true
+func (p *Processor) flagRequested(name string) bool {
+	if name == "" {
+		return false
+	}
+	requested, _ := p.flagSet.GetBool(name)
+	return requested
+}
+
+// stdout returns the writer to which the version and configuration dump
+// are written
+func (p *Processor) stdout() io.Writer {
+	if p.output == nil {
+		return os.Stdout
+	}
+	return p.output
+}
+
+// FlagSet returns the flag set to which the configuration was bound
+func (p *Processor) FlagSet() *pflag.FlagSet {
+	return p.flagSet
+}
+
+// Viper returns the viper instance to which the configuration was bound
+func (p *Processor) Viper() *viper.Viper {
+	return p.viper
+}
+
+// Parse parses the given command line arguments, which should not include
+// the program name, using the processor's flag set
+func (p *Processor) Parse(args []string) error {
+	return p.flagSet.Parse(args)
+}
+
+// Apply resolves the configuration values from viper, i.e. after flags,
+// environment variables, and defaults have been considered, into the
+// configuration specification and then runs the validators referenced
+// by `validate` tags, checks `exclusiveBool` groups, and checks that each
+// `required` field was set. The errors from all validations are aggregated
+// and returned as Errors.
+//
+// Hooks added using AddHook are run in phase order: PhaseNormalize and
+// PhaseDerive hooks after the values are set, returning the errors of the
+// first phase in which any hook failed, and PhaseValidate hooks after the
+// built in validations, whose errors are aggregated with theirs.
+//
+// Environment values of the form `@scheme:ref` are resolved using the
+// resolver registered for the scheme, see RegisterValueResolver. When an
+// order was specified using WithPrecedence, each value is resolved from
+// the first source in that order that provides one rather than from viper.
+// The resolved value of a field with a `secretFile` tag is the path of the
+// file whose contents are loaded into the field. The resolved value of a
+// field with a `decrypt` tag is then decrypted using the decryptor
+// registered with that name, see RegisterDecryptor. A field without a
+// value, including a default, is then set to the value returned by its
+// provider, if set, and a field with a `deriveFrom` tag, without a value
+// other than a default, to the value derived from the field it names, see
+// RegisterDerive.
+//
+// If the version flag was set, the version is written and ErrVersion is
+// returned before any values are resolved or validated. If the flag
+// registered by WithConfigDumpFlag was set, the resolved configuration is
+// written and ErrConfigDump is returned before any values are validated.
+func (p *Processor) Apply() error {
+	if p.version != "" && p.flagRequested(versionFlag) {
+		fmt.Fprintf(p.stdout(), "%s version %s\n", p.name, p.version)
+		return ErrVersion
+	}
+
+	resolve, err := p.resolver()
+	if err != nil {
+		return err
+	}
+
+	specElem := reflect.ValueOf(p.spec).Elem()
+	installImplementations(specElem, p.implementations)
+	for _, f := range p.fields {
+		if err := p.applyField(resolve, f, fieldByIndex(specElem, f.index)); err != nil {
+			return err
+		}
+	}
+	for _, b := range p.bound {
+		if err := p.applyField(resolve, b.field, b.target); err != nil {
+			return err
+		}
+	}
+	if err := p.applyProviders(specElem); err != nil {
+		return err
+	}
+	if err := p.applyDerivations(specElem); err != nil {
+		return err
+	}
+
+	// Values are normalized, and derived values set, before they are
+	// dumped or validated
+	for _, phase := range []Phase{PhaseNormalize, PhaseDerive} {
+		if errs := p.runHooks(phase); len(errs) > 0 {
+			return errs
+		}
+	}
+
+	if p.flagRequested(p.dumpFlag) {
+		data, err := p.DumpConfig("yaml")
+		if err != nil {
+			return err
+		}
+		_, _ = p.stdout().Write(data)
+		return ErrConfigDump
+	}
+
+	var errs Errors
+	for _, f := range p.fields {
+		value, ok := f.value(specElem)
+		if !ok {
+			continue
+		}
+		errs = append(errs, validateConstraints(f, value)...)
+		errs = append(errs, validateField(f, value)...)
+	}
+	errs = append(errs, validateExclusiveBools(p.fields, specElem)...)
+	errs = append(errs, validateRequired(p.fields, p.isSet)...)
+	errs = append(errs, p.validateRequiredEnv()...)
+	errs = append(errs, p.runHooks(PhaseValidate)...)
+	return errs.errorOrNil()
+}
+
+// emptyEnvIsTrue returns true if WithEmptyEnvIsTrue is set and the field is
+// a boolean whose environment variable is set but empty and whose flag was
+// not set
+func (p *Processor) emptyEnvIsTrue(f *field) bool {
+	if p.options.Flags&WithEmptyEnvIsTrue == 0 || f.typ.Kind() != reflect.Bool || f.env == "" {
+		return false
+	}
+	if f.long != "" && changedFlag(p.flagSet, f) != nil {
+		return false
+	}
+	value, ok := os.LookupEnv(f.env)
+	return ok && value == ""
+}
+
+// isSet returns true if a value for the field was set, by a positional
+// argument or by any source known to viper other than a default
+func (p *Processor) isSet(f *field) bool {
+	if _, ok := p.positional(f); ok {
+		return true
+	}
+	if _, ok := p.restArgs(f); ok {
+		return true
+	}
+	if p.emptyEnvIsTrue(f) {
+		return true
+	}
+	return p.viper.IsSet(f.read)
+}
+
+// applyField sets the target to the resolved value of the field, if any
+func (p *Processor) applyField(resolve func(*field) (interface{}, error), f *field, target reflect.Value) error {
+	val, err := p.resolveValue(resolve, f)
+	if err != nil || !val.IsValid() {
+		return err
+	}
+	if f.isRaw() {
+		p.setRaw(f, target, val.String())
+		return nil
+	}
+	target.Set(val)
+	return nil
+}
+
+// resolveValue returns the resolved value of the field as it is set by
+// Apply, or an invalid value if the field has no value. The value of a
+// pointer field is a pointer, which is nil when no value, including a
+// default, was specified, and the value of a raw field is its string.
+func (p *Processor) resolveValue(resolve func(*field) (interface{}, error), f *field) (reflect.Value, error) {
+	raw, err := resolve(f)
+	if err != nil {
+		p.options.fieldError(f, err)
+		return reflect.Value{}, configError(f, "", err)
+	}
+	if raw == nil {
+		return reflect.Value{}, nil
+	}
+
+	// A pointer is only allocated when a value, including a default, was
+	// specified for the field, otherwise it is left nil
+	if f.pointer && !p.isSet(f) && f.def == "" {
+		return reflect.Zero(reflect.PtrTo(f.typ)), nil
+	}
+	if raw, err = f.loadSecretFile(raw); err != nil {
+		p.options.fieldError(f, err)
+		return reflect.Value{}, configError(f, "secretFile", err)
+	}
+	if raw, err = f.decrypt(raw); err != nil {
+		p.options.fieldError(f, err)
+		return reflect.Value{}, configError(f, "decrypt", err)
+	}
+	if f.isRaw() {
+		return reflect.ValueOf(cast.ToString(raw)), nil
+	}
+	val, err := f.decode(raw)
+	if err == nil && f.isCount() {
+		val, err = f.limitCount(val)
+	}
+	if err != nil {
+		p.options.fieldError(f, err)
+		return reflect.Value{}, configError(f, "", err)
+	}
+	if f.expandsHome(p.options) {
+		val = reflect.ValueOf(expandHome(val.String())).Convert(f.typ)
+	}
+	if f.pointer {
+		ptr := reflect.New(f.typ)
+		ptr.Elem().Set(val)
+		val = ptr
+	}
+	return val, nil
+}
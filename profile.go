@@ -0,0 +1,45 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"reflect"
+	"strings"
+)
+
+// WithProfile specifies the active profile, e.g. "dev", so that members
+// with a `profile` tag are only processed when it lists the active profile
+func WithProfile(name string) Option {
+	return optionFunc(func(p *Processor) {
+		p.options.Profile = name
+	})
+}
+
+// inProfile returns true if a member with the given tag is processed under
+// the active profile, i.e. the member has no `profile` tag or the tag, a
+// comma separated list, includes the active profile
+func (o ProcessingOptions) inProfile(tag reflect.StructTag) bool {
+	profiles, ok := tag.Lookup("profile")
+	if !ok {
+		return true
+	}
+	for _, profile := range strings.Split(profiles, ",") {
+		if o.Profile != "" && strings.TrimSpace(profile) == o.Profile {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+type profileSpec struct {
+	Host  string `default:"localhost"`
+	Debug bool   `profile:"dev, staging"`
+	Trace struct {
+		Endpoint string
+	} `profile:"dev"`
+}
+
+func TestProfile(t *testing.T) {
+	for _, test := range []struct {
+		profile string
+		want    []string
+	}{
+		{"", []string{"host"}},
+		{"prod", []string{"host"}},
+		{"staging", []string{"debug", "host"}},
+		{"dev", []string{"debug", "host", "trace-endpoint"}},
+	} {
+		t.Run("profile="+test.profile, func(t *testing.T) {
+			options := DefaultOptions
+			options.Profile = test.profile
+			var c profileSpec
+			flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			if err := AddConfigurationTo(viper.New(), flagSet, &c, "", options, nil); err != nil {
+				t.Fatal(err)
+			}
+			var flags []string
+			flagSet.VisitAll(func(flag *pflag.Flag) {
+				flags = append(flags, flag.Name)
+			})
+			sort.Strings(flags)
+			if !reflect.DeepEqual(flags, test.want) {
+				t.Errorf("expected the flags %v, got %v", test.want, flags)
+			}
+		})
+	}
+}
+
+func TestWithProfile(t *testing.T) {
+	var c profileSpec
+	p, err := New(&c, WithDefault, WithProfile("dev"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--debug", "--trace-endpoint=localhost:4317"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Debug || c.Trace.Endpoint != "localhost:4317" {
+		t.Errorf("expected the dev members to be set, got %+v", c)
+	}
+}
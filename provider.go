@@ -0,0 +1,112 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"reflect"
+	"strings"
+)
+
+// provider a field of type `func() T`, tagged with the name of its
+// companion field, whose function is called by Apply to compute the value
+// of the companion when no value was otherwise specified for it
+type provider struct {
+	field     *field
+	companion *field
+}
+
+// isProvider returns true if the field is tagged as providing the value of
+// a companion field, in which case it is not bound
+func isProvider(fieldType reflect.StructField) bool {
+	return fieldType.Tag.Get("provides") != ""
+}
+
+// describeProviders returns the providers of the configSpecification, each
+// paired with its companion, a sibling field in the same struct, from the
+// given fields. It is an error if a provider is not a function without
+// arguments that returns a single value assignable to its companion.
+func describeProviders(configSpecification interface{}, prefix string, options ProcessingOptions, fields []*field) ([]provider, error) {
+	all, err := describeAllFields(configSpecification, prefix, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var providers []provider
+	for _, f := range all {
+		name := f.tag.Get("provides")
+		if !f.ignored || name == "" || !options.inProfile(f.tag) || isTrue(f.tag.Get("ignored")) {
+			continue
+		}
+		companion, ok := sibling(fields, f, name)
+		if !ok {
+			return nil, configErrorf(f, "provides", "unknown field '%s'", siblingName(f, name))
+		}
+		if f.typ.Kind() != reflect.Func || f.typ.NumIn() != 0 || f.typ.NumOut() != 1 || !f.typ.Out(0).AssignableTo(companion.typ) {
+			return nil, configErrorf(f, "provides", "provider must be of type 'func() %s'", companion.typ)
+		}
+		providers = append(providers, provider{field: f, companion: companion})
+	}
+	return providers, nil
+}
+
+// siblingName returns the path of the named member of the struct that
+// contains the field, e.g. `Server.Host` for the name `Host` and the field
+// `Server.Port`
+func siblingName(f *field, name string) string {
+	if i := strings.LastIndex(f.name, "."); i >= 0 {
+		return f.name[:i+1] + name
+	}
+	return name
+}
+
+// sibling returns the field, from the given fields, that is the named
+// member of the struct that contains the field
+func sibling(fields []*field, f *field, name string) (*field, bool) {
+	path := siblingName(f, name)
+	for _, c := range fields {
+		if c.name == path {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// applyProviders sets the companion of each provider, whose function is
+// set, to the value returned by the function, unless a value, including a
+// default, was provided for the companion by any source
+func (p *Processor) applyProviders(specElem reflect.Value) error {
+	if len(p.providers) == 0 {
+		return nil
+	}
+	file, err := p.fileConfig()
+	if err != nil {
+		return err
+	}
+	for _, pr := range p.providers {
+		fn := fieldByIndex(specElem, pr.field.index)
+		if fn.IsNil() || pr.companion.def != "" || p.sourceOf(pr.companion, file, allSources) != DefaultSource {
+			continue
+		}
+		val := fn.Call(nil)[0]
+		if pr.companion.pointer {
+			ptr := reflect.New(pr.companion.typ)
+			ptr.Elem().Set(val)
+			val = ptr
+		}
+		fieldByIndex(specElem, pr.companion.index).Set(val)
+	}
+	return nil
+}
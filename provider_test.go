@@ -0,0 +1,127 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+type providerSpec struct {
+	Hostname     string
+	HostnameFunc func() string `provides:"Hostname"`
+	Workers      int           `default:"4"`
+	WorkersFunc  func() int    `provides:"Workers"`
+	Server       struct {
+		Address     *string
+		AddressFunc func() string `provides:"Address"`
+	}
+}
+
+func TestProvides(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		args     []string
+		env      string
+		hostname string
+		called   int
+	}{
+		{"unset", nil, "", "provided", 2},
+		{"flag", []string{"--hostname=flag"}, "", "flag", 1},
+		{"env", nil, "env", "env", 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if test.env != "" {
+				os.Setenv("PROV_HOSTNAME", test.env)
+				defer os.Unsetenv("PROV_HOSTNAME")
+			}
+			called := 0
+			var c providerSpec
+			c.HostnameFunc = func() string { called++; return "provided" }
+			c.WorkersFunc = func() int { called++; return 16 }
+			c.Server.AddressFunc = func() string { called++; return "0.0.0.0:80" }
+
+			p, err := New(&c, WithDefault, WithPrefix("PROV"), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.FlagSet().Lookup("hostname-func") != nil {
+				t.Error("expected no flag for a provider")
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if c.Hostname != test.hostname {
+				t.Errorf("expected Hostname to be '%s', got '%s'", test.hostname, c.Hostname)
+			}
+			if c.Workers != 4 {
+				t.Errorf("expected the default of Workers to be used, got %d", c.Workers)
+			}
+			if c.Server.Address == nil || *c.Server.Address != "0.0.0.0:80" {
+				t.Errorf("expected the nested Address to be provided, got %v", c.Server.Address)
+			}
+			if called != test.called {
+				t.Errorf("expected %d providers to be called, got %d", test.called, called)
+			}
+		})
+	}
+}
+
+func TestProvidesUnsetFunc(t *testing.T) {
+	var c providerSpec
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Hostname != "" || c.Server.Address != nil {
+		t.Errorf("expected nothing to be provided, got '%s' and %v", c.Hostname, c.Server.Address)
+	}
+}
+
+func TestProvidesInvalid(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		spec interface{}
+		want string
+	}{
+		{"unknown", &struct {
+			HostFunc func() string `provides:"Host"`
+		}{}, "unknown field 'Host'"},
+		{"type", &struct {
+			Port     int
+			PortFunc func() string `provides:"Port"`
+		}{}, "provider must be of type 'func() int'"},
+		{"arguments", &struct {
+			Port     int
+			PortFunc func(int) int `provides:"Port"`
+		}{}, "provider must be of type 'func() int'"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New(test.spec, WithDefault, WithViper(viper.New()))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("expected the error '%s', got '%v'", test.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+type codec struct {
+	name string
+}
+
+func TestRawFields(t *testing.T) {
+	var c struct {
+		Codec   codec             `raw:"true" default:"gzip"`
+		Port    int               `raw:"true" help:"not converted"`
+		Mode    string            `raw:"true" default:"fast"`
+		Labels  map[string]string `raw:"true"`
+		Ignored string
+	}
+	os.Setenv("RAW_PORT", "not-a-number")
+	defer os.Unsetenv("RAW_PORT")
+
+	p, err := New(&c, WithDefault, WithPrefix("RAW"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ := p.FlagSet().Lookup("codec").Value.Type(); typ != "string" {
+		t.Errorf("expected a raw flag to be a string flag, got '%s'", typ)
+	}
+	if err := p.Parse([]string{"--labels=a=1,b=2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{"Codec": "gzip", "Port": "not-a-number", "Mode": "fast", "Labels": "a=1,b=2"} {
+		if got, ok := p.RawValue(name); !ok || got != want {
+			t.Errorf("expected the raw value of %s to be '%s', got '%s'", name, want, got)
+		}
+	}
+	if c.Port != 0 || c.Codec.name != "" {
+		t.Errorf("expected raw fields that are not strings to be left unset, got %+v", c)
+	}
+	if c.Mode != "fast" {
+		t.Errorf("expected a raw string field to be set, got '%s'", c.Mode)
+	}
+	if _, ok := p.RawValue("Ignored"); ok {
+		t.Error("expected no raw value for a field that is not raw")
+	}
+}
@@ -0,0 +1,189 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// addition is a configuration specification bound to a processor using Add
+type addition struct {
+	spec    interface{}
+	prefix  string
+	options ProcessingOptions
+	fields  []*field
+
+	// flags the flags that were registered by Add, including aliases
+	flags map[string]bool
+}
+
+// Add binds an additional configuration specification, e.g. that of a
+// plugin, to the processor's flag set and viper instance, as
+// AddConfigurationTo does, so that it can later be removed using Remove.
+// The specification is not set by Apply, but can be populated using
+// PopulateFrom with the processor's viper instance.
+func (p *Processor) Add(configSpecification interface{}, prefix string, options ProcessingOptions) error {
+	if _, ok := p.addition(configSpecification, prefix); ok {
+		return fmt.Errorf("configuration specification already added with prefix '%s'", prefix)
+	}
+
+	existing := map[string]bool{}
+	p.flagSet.VisitAll(func(flag *pflag.Flag) {
+		existing[flag.Name] = true
+	})
+	fields, err := addConfiguration(p.viper, p.flagSet, configSpecification, prefix, options)
+	if err != nil {
+		return err
+	}
+	flags := map[string]bool{}
+	p.flagSet.VisitAll(func(flag *pflag.Flag) {
+		if !existing[flag.Name] {
+			flags[flag.Name] = true
+		}
+	})
+
+	p.added = append(p.added, addition{
+		spec:    configSpecification,
+		prefix:  prefix,
+		options: options,
+		fields:  fields,
+		flags:   flags,
+	})
+	return nil
+}
+
+// addition returns the index of the configuration specification added
+// with the given prefix
+func (p *Processor) addition(configSpecification interface{}, prefix string) (int, bool) {
+	for i, a := range p.added {
+		if a.spec == configSpecification && a.prefix == prefix {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// Remove removes the flags and viper keys of a configuration specification
+// bound using Add with the same prefix, e.g. when a long running host
+// unloads a plugin. As neither pflag nor viper can forget a flag or a key,
+// the processor's flag set and viper instance are rebuilt in place:
+//
+// The flag set is rebuilt from its remaining flags, which keep their values,
+// with the same name, usage function, sorting, normalization, and output.
+// Whether it was parsed and its arguments are not kept, so it should be
+// parsed again if needed. A flag set passed to WithFlagSet cannot be
+// rebuilt, as pflag does not expose its name.
+//
+// The viper instance is rebuilt with the configuration read by the
+// processor, or the configuration file it used, and the bindings of the
+// processor's specification and of those added and not removed. Values set
+// directly on the viper instance, e.g. using Set, are not kept.
+func (p *Processor) Remove(configSpecification interface{}, prefix string) error {
+	idx, ok := p.addition(configSpecification, prefix)
+	if !ok {
+		return fmt.Errorf("configuration specification was not added with prefix '%s'", prefix)
+	}
+	if p.flagSetName == "" {
+		return errors.New("a flag set passed to WithFlagSet cannot be rebuilt to remove a configuration")
+	}
+	removed := p.added[idx]
+	remaining := append(append([]addition{}, p.added[:idx]...), p.added[idx+1:]...)
+
+	rebuilt := pflag.NewFlagSet(p.flagSetName, pflag.ContinueOnError)
+	rebuilt.Usage = p.flagSet.Usage
+	rebuilt.SortFlags = p.flagSet.SortFlags
+	rebuilt.ParseErrorsWhitelist = p.flagSet.ParseErrorsWhitelist
+	rebuilt.SetNormalizeFunc(p.flagSet.GetNormalizeFunc())
+	if p.output != nil {
+		rebuilt.SetOutput(p.output)
+	}
+	p.flagSet.VisitAll(func(flag *pflag.Flag) {
+		if !removed.flags[flag.Name] {
+			rebuilt.AddFlag(flag)
+		}
+	})
+
+	v, err := p.rebuildViper(remaining)
+	if err != nil {
+		return err
+	}
+	*p.flagSet = *rebuilt
+	*p.viper = *v
+	p.added = remaining
+	return nil
+}
+
+// rebuildViper returns a new viper instance to which the configuration read
+// by the processor and the bindings of the processor's specification, the
+// values bound using Bind, and the given additions are bound
+func (p *Processor) rebuildViper(additions []addition) (*viper.Viper, error) {
+	v := viper.NewWithOptions(viper.KeyDelimiter(p.options.keyDelimiter()))
+	if p.config != nil || len(p.configFiles) > 0 {
+		if err := p.readConfig(v); err != nil {
+			return nil, err
+		}
+	} else if path := p.viper.ConfigFileUsed(); path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := append([]*field{}, p.fields...)
+	for _, b := range p.bound {
+		fields = append(fields, b.field)
+	}
+	if err := p.rebind(v, fields, p.options); err != nil {
+		return nil, err
+	}
+	for _, a := range additions {
+		if a.options.Flags&WithoutViper != 0 {
+			continue
+		}
+		if err := p.rebind(v, a.fields, a.options); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// rebind binds the fields, whose flags are already registered in the
+// processor's flag set, to the viper instance as they were bound when
+// added. Each field is bound using a scratch flag set, so that its default
+// and environment variables are bound exactly as before, and its key is
+// then bound to the registered flag. Nothing is logged or traced again.
+func (p *Processor) rebind(v *viper.Viper, fields []*field, options ProcessingOptions) error {
+	options.Logger = nil
+	options.Trace = nil
+	options.Flags &^= WithDebug | WithDefaultRoundTripCheck
+	scratch := pflag.NewFlagSet("", pflag.ContinueOnError)
+	for _, f := range fields {
+		if err := bindField(v, scratch, f, options); err != nil {
+			return err
+		}
+		if f.long == "" || scratch.Lookup(f.long) == nil {
+			continue
+		}
+		if flag := p.flagSet.Lookup(f.long); flag != nil {
+			bindFlag(v, p.flagSet, f, flag)
+		}
+	}
+	return nil
+}
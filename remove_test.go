@@ -0,0 +1,176 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+type removeHost struct {
+	Name string `default:"host"`
+}
+
+type removePluginA struct {
+	Foo   string `default:"a" aliases:"OldFoo"`
+	Level int    `default:"1"`
+}
+
+type removePluginB struct {
+	Bar string `default:"b"`
+}
+
+func flagNames(flagSet *pflag.FlagSet) []string {
+	var names []string
+	flagSet.VisitAll(func(flag *pflag.Flag) {
+		names = append(names, flag.Name)
+	})
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(v *viper.Viper) []string {
+	keys := v.AllKeys()
+	sort.Strings(keys)
+	return keys
+}
+
+func TestRemove(t *testing.T) {
+	v := viper.New()
+	p, err := New(&removeHost{}, WithDefault, WithViper(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	flagSet := p.FlagSet()
+
+	a, b := &removePluginA{}, &removePluginB{}
+	if err := p.Add(a, "A", DefaultOptions); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Add(b, "B", DefaultOptions); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"bar", "foo", "level", "name", "old-foo"}; !reflect.DeepEqual(flagNames(flagSet), want) {
+		t.Fatalf("expected flags %v, got %v", want, flagNames(flagSet))
+	}
+
+	if err := p.Remove(a, "A"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The flag set and viper instance held by the caller are updated
+	if want := []string{"bar", "name"}; !reflect.DeepEqual(flagNames(flagSet), want) {
+		t.Errorf("expected flags %v after removal, got %v", want, flagNames(flagSet))
+	}
+	if want := []string{"bar", "name"}; !reflect.DeepEqual(sortedKeys(v), want) {
+		t.Errorf("expected keys %v after removal, got %v", want, sortedKeys(v))
+	}
+	if got := v.GetString("bar"); got != "b" {
+		t.Errorf("expected the default of the remaining key to be bound, got '%s'", got)
+	}
+
+	// The removed flags can be registered again
+	if err := p.Add(a, "A", DefaultOptions); err != nil {
+		t.Fatalf("expected the removed configuration to be added again, got %v", err)
+	}
+	if got := v.GetString("foo"); got != "a" {
+		t.Errorf("expected the default of the added key to be bound, got '%s'", got)
+	}
+}
+
+func TestRemoveKeepsValues(t *testing.T) {
+	os.Setenv("B_BAR", "from-env")
+	defer os.Unsetenv("B_BAR")
+
+	v := viper.New()
+	p, err := New(&removeHost{}, WithDefault, WithViper(v),
+		WithConfigReader(strings.NewReader("level: 5\n"), "yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := &removePluginA{}, &removePluginB{}
+	if err := p.Add(a, "A", DefaultOptions); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Add(b, "B", DefaultOptions); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--name", "from-flag", "--foo", "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Remove(a, "A"); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.GetString("name"); got != "from-flag" {
+		t.Errorf("expected the parsed flag to be kept, got '%s'", got)
+	}
+	if got := v.GetString("bar"); got != "from-env" {
+		t.Errorf("expected the environment variable to be bound, got '%s'", got)
+	}
+	if got := v.GetInt("level"); got != 5 {
+		t.Errorf("expected the configuration to be read again, got %d", got)
+	}
+
+	var host removeHost
+	if err := PopulateFrom(v, &host, "", DefaultOptions); err != nil {
+		t.Fatal(err)
+	}
+	if host.Name != "from-flag" {
+		t.Errorf("expected the host to be populated from the flag, got '%s'", host.Name)
+	}
+}
+
+func TestRemoveErrors(t *testing.T) {
+	p, err := New(&removeHost{}, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &removePluginA{}
+	if err := p.Remove(a, "A"); err == nil {
+		t.Error("expected an error removing a configuration that was not added")
+	}
+	if err := p.Add(a, "A", DefaultOptions); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Add(a, "A", DefaultOptions); err == nil {
+		t.Error("expected an error adding a configuration twice")
+	}
+	if err := p.Remove(a, "B"); err == nil {
+		t.Error("expected an error removing a configuration with a different prefix")
+	}
+
+	external := pflag.NewFlagSet("external", pflag.ContinueOnError)
+	p, err = New(&removeHost{}, WithDefault, WithViper(viper.New()), WithFlagSet(external))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Add(a, "A", DefaultOptions); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Remove(a, "A"); err == nil {
+		t.Error("expected an error removing a configuration from a flag set passed to WithFlagSet")
+	}
+	if external.Lookup("foo") == nil {
+		t.Error("expected the flag set to be left unchanged")
+	}
+}
@@ -0,0 +1,99 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// isRequired returns true if the field is tagged as requiring a value
+func (f *field) isRequired() bool {
+	return isTrue(f.tag.Get("required"))
+}
+
+// requiredError returns the error reported for a required field that was
+// not set, naming the environment variable and flag that can be used to
+// set it
+func requiredError(f *field) error {
+	var hints []string
+	if f.env != "" {
+		hints = append(hints, fmt.Sprintf("environment variable '%s'", f.env))
+	}
+	if f.long != "" && f.supported() {
+		hints = append(hints, fmt.Sprintf("flag '--%s'", f.long))
+	}
+	if len(hints) == 0 {
+		hints = append(hints, fmt.Sprintf("key '%s'", f.read))
+	}
+	return configErrorf(f, "required", "required value not set, set the %s", strings.Join(hints, " or the "))
+}
+
+// validateRequired checks that each required field was set, as determined
+// by the given function
+func validateRequired(fields []*field, isSet func(*field) bool) Errors {
+	var errs Errors
+	for _, f := range fields {
+		if f.isRequired() && !isSet(f) {
+			errs = append(errs, requiredError(f))
+		}
+	}
+	return errs
+}
+
+// Validate checks that each member of the specified configSpecification
+// interface tagged `required:"true"` was set, e.g. by a flag, an environment
+// variable, or a configuration file, in viper's global instance, using the
+// keys derived by AddConfiguration with the same prefix and options. A value
+// that was explicitly set to the zero value of its type, e.g. `--count=0`,
+// is present. The errors for all missing members are returned as Errors.
+func Validate(configSpecification interface{}, prefix string, options ProcessingOptions) error {
+	return ValidateFrom(viper.GetViper(), configSpecification, prefix, options)
+}
+
+// ValidateFrom is as Validate, but checks the values set in the given viper
+// instance, as passed to AddConfigurationTo, rather than viper's global
+// instance.
+func ValidateFrom(v *viper.Viper, configSpecification interface{}, prefix string, options ProcessingOptions) error {
+	fields, err := describeFields(configSpecification, prefix, options)
+	if err != nil {
+		return err
+	}
+	return validateRequired(fields, func(f *field) bool {
+		return v.IsSet(f.read)
+	}).errorOrNil()
+}
+
+// requireStrict returns an error for each required field that has no help
+// or no environment variable, as such fields cannot be documented or set in
+// a container
+func requireStrict(fields []*field) error {
+	var errs Errors
+	for _, f := range fields {
+		if !f.isRequired() {
+			continue
+		}
+		if f.help == "" {
+			errs = append(errs, configErrorf(f, "help", "required fields must have help"))
+		}
+		if f.env == "" {
+			errs = append(errs, configErrorf(f, "env", "required fields must have an environment variable"))
+		}
+	}
+	return errs.errorOrNil()
+}
@@ -0,0 +1,167 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestStrictRequired(t *testing.T) {
+	var c struct {
+		Token    string `required:"true" help:"the API token"`
+		Password string `required:"true"`
+		Region   string `required:"true" long:"region" env:""`
+		Optional string
+	}
+	options := DefaultOptions
+	options.Flags = GenerateFlag | WithStrictRequired
+
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	err := AddConfigurationTo(viper.New(), flagSet, &c, "APP", options, nil)
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("expected Errors, got '%v'", err)
+	}
+	var got []string
+	for _, e := range errs {
+		var cerr *ConfigError
+		if !errors.As(e, &cerr) {
+			t.Fatalf("expected a ConfigError, got '%v'", e)
+		}
+		got = append(got, cerr.Field+":"+cerr.Tag)
+	}
+	want := []string{"Token:env", "Password:help", "Password:env", "Region:help", "Region:env"}
+	if len(got) != len(want) {
+		t.Fatalf("expected the errors %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected the errors %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestStrictRequiredSatisfied(t *testing.T) {
+	var c struct {
+		Token    string `required:"true" help:"the API token"`
+		Optional string
+	}
+	options := DefaultOptions
+	options.Flags |= WithStrictRequired
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := AddConfigurationTo(viper.New(), flagSet, &c, "APP", options, nil); err != nil {
+		t.Errorf("expected no errors, got '%v'", err)
+	}
+
+	options.Flags &^= WithStrictRequired
+	var lax struct {
+		Token string `required:"true"`
+	}
+	flagSet = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := AddConfigurationTo(viper.New(), flagSet, &lax, "APP", options, nil); err != nil {
+		t.Errorf("expected no errors without WithStrictRequired, got '%v'", err)
+	}
+}
+
+func TestRequiredEnv(t *testing.T) {
+	type spec struct {
+		Token string `requiredEnv:"true"`
+		Level string `requiredEnv:"true" default:"info"`
+	}
+	for _, test := range []struct {
+		name string
+		env  map[string]string
+		args []string
+		file string
+		want []string
+	}{
+		{name: "env", env: map[string]string{"REQ_TOKEN": "t", "REQ_LEVEL": "debug"}},
+		{name: "default", env: map[string]string{"REQ_TOKEN": "t"}},
+		{name: "missing", want: []string{"field 'Token': required value not set, set the environment variable 'REQ_TOKEN'"}},
+		{name: "flag", env: map[string]string{"REQ_LEVEL": "debug"}, args: []string{"--token=t"},
+			want: []string{"field 'Token': value must be set by the environment variable 'REQ_TOKEN', not by the flag '--token'"}},
+		{name: "file", env: map[string]string{"REQ_TOKEN": "t"}, file: "level: warn",
+			want: []string{"field 'Level': value must be set by the environment variable 'REQ_LEVEL', not by the configuration key 'level'"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+			options := []Option{WithDefault, WithPrefix("REQ"), WithViper(viper.New())}
+			if test.file != "" {
+				options = append(options, WithConfigReader(strings.NewReader(test.file), "yaml"))
+			}
+			var c spec
+			p, err := New(&c, options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			for _, check := range []func() error{p.Validate, p.Apply} {
+				err := check()
+				if len(test.want) == 0 {
+					if err != nil {
+						t.Errorf("expected no errors, got '%v'", err)
+					}
+					continue
+				}
+				errs, ok := err.(Errors)
+				if !ok || len(errs) != len(test.want) {
+					t.Fatalf("expected the errors %q, got '%v'", test.want, err)
+				}
+				for i := range test.want {
+					if errs[i].Error() != test.want[i] {
+						t.Errorf("expected the error '%s', got '%s'", test.want[i], errs[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRequiredEnvWithRequired(t *testing.T) {
+	var c struct {
+		Token string `required:"true" requiredEnv:"true"`
+	}
+	err := applyArgs(t, &c)
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("expected only the required error, got '%v'", err)
+	}
+	var cerr *ConfigError
+	if errors.As(errs[0], &cerr) && cerr.Tag == "requiredEnv" {
+		t.Errorf("expected the required error rather than requiredEnv, got '%v'", errs[0])
+	}
+}
+
+func TestRequiredEnvWithoutEnv(t *testing.T) {
+	type spec struct {
+		Token string `requiredEnv:"true"`
+	}
+	_, err := New(&spec{}, GenerateFlag, WithViper(viper.New()))
+	if err == nil || !strings.Contains(err.Error(), "requiredEnv is only valid for fields with an environment variable") {
+		t.Errorf("expected an error for requiredEnv without an environment variable, got '%v'", err)
+	}
+}
@@ -0,0 +1,73 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// isRequiredEnv returns true if the field is tagged as requiring its value,
+// unless it has a default, to be provided by its environment variable, e.g.
+// a secret that policy requires to be injected into the environment
+func (f *field) isRequiredEnv() bool {
+	return isTrue(f.tag.Get("requiredEnv"))
+}
+
+// checkRequiredEnv returns an error if the field's `requiredEnv` tag is
+// specified for a field that has no environment variable
+func (f *field) checkRequiredEnv() error {
+	if f.isRequiredEnv() && f.env == "" {
+		return fmt.Errorf("requiredEnv is only valid for fields with an environment variable")
+	}
+	return nil
+}
+
+// validateRequiredEnv checks that the value of each field tagged
+// `requiredEnv` was provided by its environment variable or, if no other
+// source provided a value, by its default
+func (p *Processor) validateRequiredEnv() Errors {
+	var errs Errors
+	var file *viper.Viper
+	loaded := false
+	for _, f := range p.fields {
+		if !f.isRequiredEnv() {
+			continue
+		}
+
+		// The configuration is only read again when it is needed to
+		// determine the source of a value
+		if !loaded {
+			var err error
+			if file, err = p.fileConfig(); err != nil {
+				return append(errs, err)
+			}
+			loaded = true
+		}
+		switch src := p.sourceOf(f, file, allSources); src {
+		case EnvSource:
+		case DefaultSource:
+			// A required field without a value is reported as such
+			if f.def == "" && !f.isRequired() {
+				errs = append(errs, configErrorf(f, "requiredEnv", "required value not set, set the environment variable '%s'", f.env))
+			}
+		default:
+			errs = append(errs, configErrorf(f, "requiredEnv", "value must be set by the environment variable '%s', not by the %s", f.env, describeSource(f, src)))
+		}
+	}
+	return errs
+}
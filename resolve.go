@@ -0,0 +1,96 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ValueResolverFunc resolves a reference, the part of an environment value
+// following `@scheme:`, to the value it refers to
+type ValueResolverFunc func(ref string) (string, error)
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]ValueResolverFunc{
+		"file": resolveFile,
+		"env":  resolveEnv,
+	}
+)
+
+// RegisterValueResolver registers a resolver for environment values of the
+// form `@scheme:ref`. When such a value is provided by an environment
+// variable, Apply replaces it with the result of the resolver. Resolvers
+// for the `file` and `env` schemes are registered by default.
+func RegisterValueResolver(scheme string, fn ValueResolverFunc) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = fn
+}
+
+// lookupValueResolver returns the resolver registered for the given scheme
+func lookupValueResolver(scheme string) (ValueResolverFunc, bool) {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	fn, ok := resolvers[scheme]
+	return fn, ok
+}
+
+// resolveFile reads the contents of the referenced file, removing a single
+// trailing newline as is typically present in mounted secrets
+func resolveFile(ref string) (string, error) {
+	data, err := ioutil.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(value, "\r"), nil
+}
+
+// resolveEnv returns the value of the referenced environment variable
+func resolveEnv(ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable '%s' is not set", ref)
+	}
+	return value, nil
+}
+
+// resolveValue resolves a value of the form `@scheme:ref` using the
+// resolver registered for the scheme. Values that are not of that form, or
+// whose scheme has no registered resolver, are returned unchanged.
+func resolveValue(value string) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return value, nil
+	}
+	parts := strings.SplitN(value[1:], ":", 2)
+	if len(parts) != 2 {
+		return value, nil
+	}
+	fn, ok := lookupValueResolver(parts[0])
+	if !ok {
+		return value, nil
+	}
+	resolved, err := fn(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve '%s': %w", value, err)
+	}
+	return resolved, nil
+}
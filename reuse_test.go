@@ -0,0 +1,78 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestReuseExistingFlags(t *testing.T) {
+	type spec struct {
+		Port int    `default:"80"`
+		Host string `default:"localhost"`
+	}
+	options := DefaultOptions
+	options.Flags |= WithReuseExistingFlags
+
+	for _, tc := range []struct {
+		name string
+		args []string
+		port int
+	}{
+		{"default", nil, 9090},
+		{"set", []string{"--port", "8080"}, 8080},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var c spec
+			v := viper.New()
+			flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flagSet.Int("port", 9090, "the existing port flag")
+			if err := AddConfigurationTo(v, flagSet, &c, "", options, nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := flagSet.Parse(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := PopulateFrom(v, &c, "", options); err != nil {
+				t.Fatal(err)
+			}
+			if c.Port != tc.port {
+				t.Errorf("expected Port to be %d, got %d", tc.port, c.Port)
+			}
+			if c.Host != "localhost" {
+				t.Errorf("expected Host to be 'localhost', got '%s'", c.Host)
+			}
+			if usage := flagSet.Lookup("port").Usage; usage != "the existing port flag" {
+				t.Errorf("expected the existing flag to be kept, got the usage '%s'", usage)
+			}
+		})
+	}
+}
+
+func TestRedefinedFlagWithoutReuse(t *testing.T) {
+	var c struct {
+		Port int `default:"80"`
+	}
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flagSet.Int("port", 9090, "the existing port flag")
+	err := AddConfigurationTo(viper.New(), flagSet, &c, "", DefaultOptions, nil)
+	if err == nil || !strings.Contains(err.Error(), "duplicate flag '--port'") {
+		t.Errorf("expected a duplicate flag error, got '%v'", err)
+	}
+}
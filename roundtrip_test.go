@@ -0,0 +1,86 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// roundTripWarnings returns the messages logged when the specification is
+// bound with the given flags
+func roundTripWarnings(t *testing.T, spec interface{}, flags Flags) []string {
+	t.Helper()
+	var logged []string
+	options := DefaultOptions
+	options.Flags = flags
+	options.Logger = func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+	viper.Reset()
+	defer viper.Reset()
+	if err := AddConfiguration(pflag.NewFlagSet("test", pflag.ContinueOnError), spec, "", options, nil); err != nil {
+		t.Fatal(err)
+	}
+	return logged
+}
+
+func TestDefaultRoundTripCheck(t *testing.T) {
+	var c struct {
+		Timeout  time.Duration `default:"90s"`
+		Interval time.Duration `default:"1m30s"`
+		Count    int           `default:"007"`
+		Name     string        `default:"name"`
+		Enabled  bool          `default:"true"`
+		Pin      int           `default:"0010" secret:"true"`
+	}
+
+	logged := roundTripWarnings(t, &c, WithDefault|WithDefaultRoundTripCheck)
+	want := []string{
+		"field 'Timeout': default '90s' does not round trip, parsed as '1m30s'",
+		"field 'Count': default '007' does not round trip, parsed as '7'",
+	}
+	if strings.Join(logged, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected the warnings:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(logged, "\n"))
+	}
+
+	if logged := roundTripWarnings(t, &c, WithDefault); len(logged) != 0 {
+		t.Errorf("expected no warnings without the check, got %v", logged)
+	}
+}
+
+func TestFloatRoundTripCheck(t *testing.T) {
+	var c struct {
+		Ratio   float64 `default:"1.50"`
+		Small   float32 `default:"0.1"`
+		Precise float64 `default:"0.12345678901234567890"`
+		Wide    float32 `default:"16777217"`
+	}
+	logged := roundTripWarnings(t, &c, WithDefault|WithDefaultRoundTripCheck)
+	want := []string{
+		"field 'Small': default '0.1' loses precision as float32, parsed as '0.10000000149011612'",
+		"field 'Precise': default '0.12345678901234567890' loses precision as float64, parsed as '0.12345678901234568'",
+		"field 'Wide': default '16777217' loses precision as float32, parsed as '1.6777216e+07'",
+	}
+	if strings.Join(logged, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected the warnings:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(logged, "\n"))
+	}
+}
@@ -0,0 +1,44 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+// redacted the value displayed in place of a redacted value
+const redacted = "****"
+
+// isSecret returns true if the field is tagged as holding a secret, or as
+// loaded from a secret file, whose value is redacted wherever it would
+// otherwise be displayed
+func (f *field) isSecret() bool {
+	return isTrue(f.tag.Get("secret")) || f.isSecretFile()
+}
+
+// redact returns the value to display for the field, i.e. the value itself
+// unless the field is a secret and the value is set, in which case `****`
+func (f *field) redact(value interface{}) interface{} {
+	if value == nil || !f.isSecret() {
+		return value
+	}
+	return redacted
+}
+
+// redactedDefault returns the default of the field as specified, unless
+// the field is a secret, in which case a specified default is `****`
+func (f *field) redactedDefault() string {
+	if f.def == "" || !f.isSecret() {
+		return f.def
+	}
+	return redacted
+}
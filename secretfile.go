@@ -0,0 +1,57 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/cast"
+)
+
+// isSecretFile returns true if the value of the field, e.g. from a
+// `--password-file` flag, is the path of a file whose contents are loaded
+// into the field, as for Docker and Kubernetes secret mounts
+func (f *field) isSecretFile() bool {
+	return isTrue(f.tag.Get("secretFile"))
+}
+
+// checkSecretFile returns an error if the field's `secretFile` tag is
+// specified for a field that is not a string
+func (f *field) checkSecretFile() error {
+	if f.isSecretFile() && f.typ.Kind() != reflect.String {
+		return fmt.Errorf("secretFile is only valid for string fields")
+	}
+	return nil
+}
+
+// loadSecretFile returns the contents, less a trailing newline, of the file
+// whose path is the resolved value of a field tagged as a secret file.
+// Values of other fields, and empty paths, are returned unchanged.
+func (f *field) loadSecretFile(raw interface{}) (interface{}, error) {
+	if !f.isSecretFile() {
+		return raw, nil
+	}
+	path := cast.ToString(raw)
+	if path == "" {
+		return raw, nil
+	}
+	contents, err := resolveFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read secret file: %w", err)
+	}
+	return contents, nil
+}
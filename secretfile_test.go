@@ -0,0 +1,104 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestSecretFile(t *testing.T) {
+	password := writeConfigFile(t, "password", "s3cret\n")
+	token := writeConfigFile(t, "token", "enc:nekot")
+	os.Setenv("APP_TOKEN", token)
+	defer os.Unsetenv("APP_TOKEN")
+
+	var c struct {
+		Password string `secretFile:"true"`
+		Token    string `secretFile:"true" secret:"true" decrypt:"test-reverse"`
+		Unset    string `secretFile:"true"`
+	}
+	p, err := New(&c, WithDefault, WithPrefix("APP"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--password", password}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Password != "s3cret" {
+		t.Errorf("expected Password to be loaded from the file, got '%s'", c.Password)
+	}
+	if c.Token != "token" {
+		t.Errorf("expected Token to be loaded and decrypted, got '%s'", c.Token)
+	}
+	if c.Unset != "" {
+		t.Errorf("expected Unset to be empty, got '%s'", c.Unset)
+	}
+
+	data, err := p.DumpConfig("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cret") || !strings.Contains(string(data), `"password": "****"`) {
+		t.Errorf("expected Password to be redacted, got '%s'", data)
+	}
+}
+
+func TestSecretFileMissing(t *testing.T) {
+	var c struct {
+		Password string `secretFile:"true"`
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(os.TempDir(), "venom-missing-secret")
+	if err := p.Parse([]string{"--password", missing}); err != nil {
+		t.Fatal(err)
+	}
+	err = p.Apply()
+	var cerr *ConfigError
+	if !errors.As(firstError(err), &cerr) || cerr.Field != "Password" || cerr.Tag != "secretFile" {
+		t.Fatalf("expected a secretFile error for Password, got '%v'", err)
+	}
+	if !strings.Contains(cerr.Reason, "cannot read secret file") {
+		t.Errorf("expected the reason to describe the file, got '%s'", cerr.Reason)
+	}
+}
+
+func TestSecretFileRequiresString(t *testing.T) {
+	type spec struct {
+		Pin int `secretFile:"true"`
+	}
+	_, err := New(&spec{}, WithDefault, WithViper(viper.New()))
+	if err == nil || !strings.Contains(err.Error(), "secretFile is only valid for string fields") {
+		t.Errorf("expected an error for secretFile on an int, got '%v'", err)
+	}
+	issues, err := Lint(&spec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messages := lintMessages(issues, "Pin"); len(messages) == 0 {
+		t.Error("expected Lint to report secretFile on an int")
+	}
+}
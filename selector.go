@@ -0,0 +1,41 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"reflect"
+)
+
+// WithTagSelector specifies that only the members whose tag with the given
+// key has the given value are processed, e.g. `audience:"public"`, so that
+// a single specification can generate different command lines for
+// different audiences. All the members of a selected struct are processed.
+func WithTagSelector(key, value string) Option {
+	return optionFunc(func(p *Processor) {
+		p.options.TagSelectorKey = key
+		p.options.TagSelectorValue = value
+	})
+}
+
+// selects returns true if a member with the given tag is selected by the
+// tag selector, if any
+func (o ProcessingOptions) selects(tag reflect.StructTag) bool {
+	if o.TagSelectorKey == "" {
+		return false
+	}
+	value, ok := tag.Lookup(o.TagSelectorKey)
+	return ok && value == o.TagSelectorValue
+}
@@ -0,0 +1,106 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+type selectorSpec struct {
+	Host  string `audience:"public"`
+	Debug bool   `audience:"internal"`
+	Token string
+	TLS   struct {
+		Cert string
+		Key  string
+	} `audience:"public"`
+}
+
+func selectedFlags(t *testing.T, options ProcessingOptions) ([]string, error) {
+	t.Helper()
+	var c selectorSpec
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := AddConfigurationTo(viper.New(), flagSet, &c, "", options, nil); err != nil {
+		return nil, err
+	}
+	var flags []string
+	flagSet.VisitAll(func(flag *pflag.Flag) {
+		flags = append(flags, flag.Name)
+	})
+	sort.Strings(flags)
+	return flags, nil
+}
+
+func TestTagSelector(t *testing.T) {
+	for _, test := range []struct {
+		key, value string
+		want       []string
+	}{
+		{"audience", "public", []string{"host", "tls-cert", "tls-key"}},
+		{"audience", "internal", []string{"debug"}},
+		{"", "", []string{"debug", "host", "tls-cert", "tls-key", "token"}},
+	} {
+		options := DefaultOptions
+		options.TagSelectorKey, options.TagSelectorValue = test.key, test.value
+		flags, err := selectedFlags(t, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(flags, test.want) {
+			t.Errorf("%s=%s: expected the flags %v, got %v", test.key, test.value, test.want, flags)
+		}
+	}
+}
+
+func TestStrictTagSelector(t *testing.T) {
+	options := DefaultOptions
+	options.TagSelectorKey, options.TagSelectorValue = "audience", "partner"
+	if flags, err := selectedFlags(t, options); err != nil || len(flags) != 0 {
+		t.Errorf("expected no flags and no error, got %v and '%v'", flags, err)
+	}
+
+	options.Flags |= WithStrictSelector
+	_, err := selectedFlags(t, options)
+	if err == nil || !strings.Contains(err.Error(), `tag selector 'audience:"partner"' does not select any field`) {
+		t.Errorf("expected an error for a selector that selects nothing, got '%v'", err)
+	}
+}
+
+func TestWithTagSelector(t *testing.T) {
+	var c selectorSpec
+	p, err := New(&c, WithDefault, WithTagSelector("audience", "internal"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.FlagSet().SetOutput(&strings.Builder{})
+	if err := p.Parse([]string{"--host=example.com"}); err == nil {
+		t.Error("expected an error for a flag that is not selected")
+	}
+	if err := p.Parse([]string{"--debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Debug {
+		t.Error("expected Debug to be set")
+	}
+}
@@ -0,0 +1,237 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type schedule []time.Duration
+
+func TestDurationSlices(t *testing.T) {
+	type spec struct {
+		Backoff []time.Duration `default:"1s,5m"`
+		Retry   schedule        `default:"100ms,2s"`
+	}
+	tests := []struct {
+		name    string
+		env     string
+		args    []string
+		backoff []time.Duration
+		retry   schedule
+	}{
+		{name: "defaults", backoff: []time.Duration{time.Second, 5 * time.Minute}, retry: schedule{100 * time.Millisecond, 2 * time.Second}},
+		{name: "repeated flags", args: []string{"--backoff=1h", "--backoff=2h,3h"}, backoff: []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour}, retry: schedule{100 * time.Millisecond, 2 * time.Second}},
+		{name: "env", env: "10s,20s", backoff: []time.Duration{time.Second, 5 * time.Minute}, retry: schedule{10 * time.Second, 20 * time.Second}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.env != "" {
+				os.Setenv("SLICE_RETRY", test.env)
+				defer os.Unsetenv("SLICE_RETRY")
+			}
+			var c spec
+			p, err := New(&c, WithDefault, WithPrefix("SLICE"), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(c.Backoff, test.backoff) {
+				t.Errorf("expected Backoff to be %v, got %v", test.backoff, c.Backoff)
+			}
+			if !reflect.DeepEqual(c.Retry, test.retry) {
+				t.Errorf("expected Retry to be %v, got %v", test.retry, c.Retry)
+			}
+		})
+	}
+}
+
+func TestDurationSliceErrors(t *testing.T) {
+	var c struct {
+		Backoff []time.Duration `default:"1s,soon"`
+	}
+	if _, err := New(&c, WithDefault, WithViper(viper.New())); err == nil {
+		t.Error("expected an error for an invalid duration in the default")
+	}
+}
+
+func TestSplitListQuotes(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "", want: nil},
+		{value: "a,b", want: []string{"a", "b"}},
+		{value: `"a,b",c`, want: []string{"a,b", "c"}},
+		{value: `"say ""hi""",x`, want: []string{`say "hi"`, "x"}},
+		{value: `[a,"b,c"]`, want: []string{"a", "b,c"}},
+		{value: `a,,b`, want: []string{"a", "", "b"}},
+	}
+	for _, test := range tests {
+		if got := splitList(test.value); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected %q, got %q", test.value, test.want, got)
+		}
+	}
+}
+
+func TestQuotedStringSlice(t *testing.T) {
+	var c struct {
+		Names []string `default:"\"Doe, Jane\",Smith"`
+	}
+	v := viper.New()
+	p, err := New(&c, WithDefault, WithPrefix("QUOTE"), WithViper(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def := v.Get("names"); !reflect.DeepEqual(def, []string{"Doe, Jane", "Smith"}) {
+		t.Errorf("expected the default to be split respecting quotes, got %q", def)
+	}
+
+	os.Setenv("QUOTE_NAMES", `"a,b","c"`)
+	defer os.Unsetenv("QUOTE_NAMES")
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a,b", "c"}; !reflect.DeepEqual(c.Names, want) {
+		t.Errorf("expected Names to be %q, got %q", want, c.Names)
+	}
+
+	for _, element := range []string{"plain", "a,b", `say "hi"`} {
+		if got := splitList(quoteListElement(element)); len(got) != 1 || got[0] != element {
+			t.Errorf("expected '%s' to survive quoting, got %q", element, got)
+		}
+	}
+}
+
+type signedSliceSpec struct {
+	Offsets []int     `default:"-1,0,1"`
+	Single  []int64   `default:"-5"`
+	Weights []float64 `default:"-0.5,2.25"`
+}
+
+func TestNegativeSliceElements(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want signedSliceSpec
+	}{
+		{
+			name: "defaults",
+			want: signedSliceSpec{Offsets: []int{-1, 0, 1}, Single: []int64{-5}, Weights: []float64{-0.5, 2.25}},
+		},
+		{
+			name: "flags",
+			args: []string{"--offsets=-5", "--offsets=-6", "--single=-7,8", "--weights=-1e3"},
+			want: signedSliceSpec{Offsets: []int{-5, -6}, Single: []int64{-7, 8}, Weights: []float64{-1000}},
+		},
+		{
+			name: "env",
+			env:  map[string]string{"SLICE_OFFSETS": "-2", "SLICE_SINGLE": "-9,-10", "SLICE_WEIGHTS": "-3.5"},
+			want: signedSliceSpec{Offsets: []int{-2}, Single: []int64{-9, -10}, Weights: []float64{-3.5}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+
+			var c signedSliceSpec
+			p, err := New(&c, WithDefault, WithPrefix("SLICE"), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(c, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, c)
+			}
+		})
+	}
+}
+
+func TestSplitListWhitespace(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "1s, 5s, 30s", want: []string{"1s", "5s", "30s"}},
+		{value: "  a ,\tb  ", want: []string{"a", "b"}},
+		{value: `" a ", b`, want: []string{" a ", "b"}},
+		{value: `x" y " , z`, want: []string{"x y ", "z"}},
+		{value: "a b, c", want: []string{"a b", "c"}},
+		{value: "[80, 443]", want: []string{"80", "443"}},
+	}
+	for _, test := range tests {
+		if got := splitList(test.value); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("expected '%s' to split into %q, got %q", test.value, test.want, got)
+		}
+	}
+}
+
+func TestTrimmedLists(t *testing.T) {
+	os.Setenv("TRIM_NAMES", "alpha , beta")
+	defer os.Unsetenv("TRIM_NAMES")
+
+	var c struct {
+		Ports   []int           `default:"80, 443"`
+		Names   []string        `default:"a"`
+		Delays  []time.Duration `default:"1s, 5s, 30s"`
+		Weights []float64
+		Labels  []string
+	}
+	p, err := New(&c, WithDefault, WithPrefix("TRIM"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--weights", "0.5, 1.5", "--labels", `" x ", y`}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{80, 443}; !reflect.DeepEqual(c.Ports, want) {
+		t.Errorf("expected Ports to be %v, got %v", want, c.Ports)
+	}
+	if want := []string{"alpha", "beta"}; !reflect.DeepEqual(c.Names, want) {
+		t.Errorf("expected Names to be %q, got %q", want, c.Names)
+	}
+	if want := []time.Duration{time.Second, 5 * time.Second, 30 * time.Second}; !reflect.DeepEqual(c.Delays, want) {
+		t.Errorf("expected Delays to be %v, got %v", want, c.Delays)
+	}
+	if want := []float64{0.5, 1.5}; !reflect.DeepEqual(c.Weights, want) {
+		t.Errorf("expected Weights to be %v, got %v", want, c.Weights)
+	}
+	if want := []string{" x ", "y"}; !reflect.DeepEqual(c.Labels, want) {
+		t.Errorf("expected Labels to be %q, got %q", want, c.Labels)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build go1.21
+// +build go1.21
+
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"log/slog"
+	"reflect"
+)
+
+// slog.Level is parsed as any other text type, accepting the level names,
+// compared case insensitively and optionally with an offset, e.g. `warn+2`,
+// so only the placeholder listing the names is registered
+func init() {
+	textPlaceholders[reflect.TypeOf(slog.Level(0))] = "debug|info|warn|error"
+}
@@ -0,0 +1,71 @@
+//go:build go1.21
+// +build go1.21
+
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package venom
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestSlogLevel(t *testing.T) {
+	for _, test := range []struct {
+		args []string
+		want slog.Level
+	}{
+		{nil, slog.LevelInfo},
+		{[]string{"--log-level=DEBUG"}, slog.LevelDebug},
+		{[]string{"--log-level=warn+2"}, slog.LevelWarn + 2},
+	} {
+		var c struct {
+			LogLevel slog.Level `default:"info"`
+		}
+		p, err := New(&c, WithDefault, WithViper(viper.New()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Parse(test.args); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Apply(); err != nil {
+			t.Fatal(err)
+		}
+		if c.LogLevel != test.want {
+			t.Errorf("%v: expected the level '%s', got '%s'", test.args, test.want, c.LogLevel)
+		}
+		if usage := p.FlagSet().FlagUsages(); !strings.Contains(usage, "--log-level debug|info|warn|error") {
+			t.Errorf("expected the usage to list the level names, got:\n%s", usage)
+		}
+	}
+}
+
+func TestSlogLevelInvalid(t *testing.T) {
+	var c struct {
+		LogLevel slog.Level
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.FlagSet().SetOutput(&strings.Builder{})
+	if err := p.Parse([]string{"--log-level=loud"}); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
@@ -0,0 +1,164 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// Snapshot is a read only copy of the resolved configuration values, keyed
+// by the viper keys to which they were bound. As with viper, keys are case
+// insensitive. Changes made to viper after the snapshot was taken are not
+// reflected in the snapshot.
+type Snapshot struct {
+	values map[string]interface{}
+}
+
+// Freeze resolves the current value of each bound field, as Apply would
+// set it, and returns an immutable snapshot of those values. Viper has no
+// means to prevent further mutation, so code that must not observe later
+// changes, such as those made by viper.Set, should read from the snapshot.
+func (p *Processor) Freeze() (*Snapshot, error) {
+	resolve, err := p.resolver()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]*field, 0, len(p.fields)+len(p.bound))
+	fields = append(fields, p.fields...)
+	for _, b := range p.bound {
+		fields = append(fields, b.field)
+	}
+	values := map[string]interface{}{}
+	for _, f := range fields {
+		val, err := p.resolveValue(resolve, f)
+		if err != nil {
+			return nil, err
+		}
+		if f.pointer && val.IsValid() && !f.isRaw() {
+			val = val.Elem()
+		}
+		if !val.IsValid() {
+			continue
+		}
+		values[strings.ToLower(f.key)] = copyValue(val).Interface()
+	}
+	return &Snapshot{values: values}, nil
+}
+
+// copyValue returns a copy of the value that shares no memory with the
+// original, i.e. slices and maps, including their elements, are copied
+func copyValue(val reflect.Value) reflect.Value {
+	switch {
+	case val.Kind() == reflect.Interface && !val.IsNil():
+		return copyValue(val.Elem())
+	case val.Kind() == reflect.Slice && !val.IsNil():
+		dup := reflect.MakeSlice(val.Type(), val.Len(), val.Len())
+		for i := 0; i < val.Len(); i++ {
+			dup.Index(i).Set(copyValue(val.Index(i)))
+		}
+		return dup
+	case val.Kind() == reflect.Map && !val.IsNil():
+		dup := reflect.MakeMapWithSize(val.Type(), val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			dup.SetMapIndex(copyValue(iter.Key()), copyValue(iter.Value()))
+		}
+		return dup
+	}
+	return val
+}
+
+// Keys returns the keys in the snapshot in sorted order
+func (s *Snapshot) Keys() []string {
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// IsSet returns true if the snapshot contains a value for the key
+func (s *Snapshot) IsSet(key string) bool {
+	_, ok := s.values[strings.ToLower(key)]
+	return ok
+}
+
+// Get returns the value for the key, or nil if it is not set. Slice and map
+// values are copied so that the snapshot cannot be modified through them.
+func (s *Snapshot) Get(key string) interface{} {
+	val, ok := s.values[strings.ToLower(key)]
+	if !ok {
+		return nil
+	}
+	return copyValue(reflect.ValueOf(val)).Interface()
+}
+
+// GetString returns the value for the key as a string
+func (s *Snapshot) GetString(key string) string {
+	return cast.ToString(s.Get(key))
+}
+
+// GetBool returns the value for the key as a bool
+func (s *Snapshot) GetBool(key string) bool {
+	return cast.ToBool(s.Get(key))
+}
+
+// GetInt returns the value for the key as an int
+func (s *Snapshot) GetInt(key string) int {
+	return cast.ToInt(s.Get(key))
+}
+
+// GetInt64 returns the value for the key as an int64
+func (s *Snapshot) GetInt64(key string) int64 {
+	return cast.ToInt64(s.Get(key))
+}
+
+// GetUint returns the value for the key as a uint
+func (s *Snapshot) GetUint(key string) uint {
+	return cast.ToUint(s.Get(key))
+}
+
+// GetUint64 returns the value for the key as a uint64
+func (s *Snapshot) GetUint64(key string) uint64 {
+	return cast.ToUint64(s.Get(key))
+}
+
+// GetFloat64 returns the value for the key as a float64
+func (s *Snapshot) GetFloat64(key string) float64 {
+	return cast.ToFloat64(s.Get(key))
+}
+
+// GetDuration returns the value for the key as a time.Duration
+func (s *Snapshot) GetDuration(key string) time.Duration {
+	return cast.ToDuration(s.Get(key))
+}
+
+// GetTime returns the value for the key as a time.Time
+func (s *Snapshot) GetTime(key string) time.Time {
+	return cast.ToTime(s.Get(key))
+}
+
+// GetStringSlice returns the value for the key as a []string
+func (s *Snapshot) GetStringSlice(key string) []string {
+	return cast.ToStringSlice(s.Get(key))
+}
@@ -0,0 +1,137 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestFreeze(t *testing.T) {
+	var c struct {
+		Host    string          `default:"localhost"`
+		Timeout time.Duration   `default:"5s"`
+		Backoff []time.Duration `default:"1s,2s"`
+		Server  struct {
+			Port int `default:"80"`
+		}
+	}
+	v := viper.New()
+	p, err := New(&c, WithDefault, WithViper(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--server-port=8080"}); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := p.Freeze()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v.Set("host", "changed")
+	v.Set("server.port", 1)
+
+	if got := snap.GetString("Host"); got != "localhost" {
+		t.Errorf("expected Host to be 'localhost', got '%s'", got)
+	}
+	if got := snap.GetInt("SERVER.PORT"); got != 8080 {
+		t.Errorf("expected server.port to be 8080, got %d", got)
+	}
+	if got := snap.GetDuration("timeout"); got != 5*time.Second {
+		t.Errorf("expected timeout to be 5s, got %s", got)
+	}
+	if want := []string{"backoff", "host", "server.port", "timeout"}; !reflect.DeepEqual(snap.Keys(), want) {
+		t.Errorf("expected the keys %v, got %v", want, snap.Keys())
+	}
+	if snap.IsSet("missing") || snap.Get("missing") != nil {
+		t.Error("expected a missing key not to be set")
+	}
+
+	backoff := snap.Get("backoff").([]time.Duration)
+	backoff[0] = time.Hour
+	if got := snap.Get("backoff").([]time.Duration); got[0] != time.Second {
+		t.Errorf("expected the snapshot not to be modified through a slice, got %v", got)
+	}
+}
+
+func TestFreezeMap(t *testing.T) {
+	var c struct {
+		Labels map[string]string `default:"env=dev,tier=web"`
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := p.Freeze()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := snap.Get("labels").(map[string]string)
+	labels["env"] = "prod"
+	delete(labels, "tier")
+	want := map[string]string{"env": "dev", "tier": "web"}
+	if got := snap.Get("labels"); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the snapshot not to be modified through a map, got %v", got)
+	}
+}
+
+func TestFreezeResolvesAsApply(t *testing.T) {
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", "/home/tester")
+	defer os.Setenv("HOME", home)
+	password := writeConfigFile(t, "password", "s3cret\n")
+	token := writeConfigFile(t, "token", "enc:nekot")
+
+	var c struct {
+		Password string `secretFile:"true"`
+		Token    string `secretFile:"true" secret:"true" decrypt:"test-reverse"`
+		Data     string `path:"true"`
+		Verbose  int    `short:"v" count:"true" max:"2"`
+	}
+	p, err := New(&c, WithDefault|WithHomeExpansion, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--password", password, "--token", token, "--data=~/data", "-vvv"}); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := p.Freeze()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := snap.GetString("password"); got != "s3cret" {
+		t.Errorf("expected password to be loaded from the file, got '%s'", got)
+	}
+	if got := snap.GetString("token"); got != "token" {
+		t.Errorf("expected token to be loaded and decrypted, got '%s'", got)
+	}
+	if got := snap.GetString("data"); got != "/home/tester/data" {
+		t.Errorf("expected data to be expanded, got '%s'", got)
+	}
+	if got := snap.GetInt("verbose"); got != 2 {
+		t.Errorf("expected verbose to be limited to 2, got %d", got)
+	}
+}
@@ -0,0 +1,66 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestOnlyTagged(t *testing.T) {
+	type spec struct {
+		Host    string `help:"host to connect to"`
+		Port    int    `d:"80"`
+		Scratch string
+		Cache   string `ignored:"true"`
+		Other   string `json:"other"`
+	}
+	tests := []struct {
+		name  string
+		flags Flags
+		want  map[string]bool
+	}{
+		{
+			name:  "all",
+			flags: WithDefault,
+			want:  map[string]bool{"host": true, "port": true, "scratch": true, "cache": false, "other": true},
+		},
+		{
+			name:  "only tagged",
+			flags: WithDefault | OnlyTagged,
+			want:  map[string]bool{"host": true, "port": true, "scratch": false, "cache": false, "other": false},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := DefaultOptions
+			options.Flags = test.flags
+			viper.Reset()
+			defer viper.Reset()
+			flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			if err := AddConfiguration(flagSet, &spec{}, "", options, nil); err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range test.want {
+				if got := flagSet.Lookup(name) != nil; got != want {
+					t.Errorf("expected the flag '--%s' defined to be %t, got %t", name, want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// textPlaceholders the value placeholders displayed in the usage for text
+// types whose name does not describe the values they accept, e.g. the level
+// names accepted by slog.Level
+var textPlaceholders = map[reflect.Type]string{}
+
+// isTextType returns true if a pointer to the type implements
+// encoding.TextUnmarshaler, which is the case whether the UnmarshalText
+// method has a value or a pointer receiver, or, failing that,
+// json.Unmarshaler, see isJSONType
+func isTextType(typ reflect.Type) bool {
+	return typ.Kind() != reflect.Ptr && reflect.PtrTo(typ).Implements(textUnmarshalerType) || isJSONType(typ)
+}
+
+// parseText parses the value as a value of the given type using its
+// UnmarshalText method or, if it has none, its UnmarshalJSON method
+func parseText(typ reflect.Type, value string) (interface{}, error) {
+	ptr := reflect.New(typ)
+	if !ptr.Type().Implements(textUnmarshalerType) {
+		return parseJSON(typ, value)
+	}
+	if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// formatText renders the value using its MarshalText method, if the type
+// implements encoding.TextMarshaler, or its MarshalJSON method, if the type
+// only implements json.Marshaler, returning false otherwise
+func formatText(value reflect.Value) (string, bool) {
+	ptr := reflect.New(value.Type())
+	ptr.Elem().Set(value)
+	if !ptr.Type().Implements(textMarshalerType) {
+		return formatJSON(ptr)
+	}
+	text, err := ptr.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return fmt.Sprintf("%v", value.Interface()), true
+	}
+	return string(text), true
+}
+
+// textValue implements the pflag.Value interface for a type that
+// implements encoding.TextUnmarshaler
+type textValue struct {
+	value reflect.Value
+}
+
+func newTextValue(val interface{}) *textValue {
+	value := reflect.New(reflect.TypeOf(val)).Elem()
+	value.Set(reflect.ValueOf(val))
+	return &textValue{value: value}
+}
+
+func (t *textValue) String() string {
+	if text, ok := formatText(t.value); ok {
+		return text
+	}
+	return fmt.Sprintf("%v", t.value.Interface())
+}
+
+func (t *textValue) Set(value string) error {
+	parsed, err := parseText(t.value.Type(), value)
+	if err != nil {
+		return err
+	}
+	t.value.Set(reflect.ValueOf(parsed))
+	return nil
+}
+
+func (t *textValue) Type() string {
+	if placeholder, ok := textPlaceholders[t.value.Type()]; ok {
+		return placeholder
+	}
+	if name := t.value.Type().Name(); name != "" {
+		return strings.ToLower(name)
+	}
+	return "string"
+}
@@ -0,0 +1,88 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+type layoutSpec struct {
+	When time.Time `layout:"2006-01-02|2006-01-02T15:04:05Z07:00" default:"2021-03-04"`
+}
+
+func TestTimeLayouts(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want time.Time
+	}{
+		{name: "default", want: time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{name: "first layout", args: []string{"--when=2022-05-06"}, want: time.Date(2022, 5, 6, 0, 0, 0, 0, time.UTC)},
+		{name: "second layout", args: []string{"--when=2022-05-06T07:08:09Z"}, want: time.Date(2022, 5, 6, 7, 8, 9, 0, time.UTC)},
+		{name: "env", env: "2023-01-02T03:04:05Z", want: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.env != "" {
+				os.Setenv("LAYOUT_WHEN", test.env)
+				defer os.Unsetenv("LAYOUT_WHEN")
+			}
+			var c layoutSpec
+			p, err := New(&c, WithDefault, WithPrefix("LAYOUT"), WithViper(viper.New()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Apply(); err != nil {
+				t.Fatal(err)
+			}
+			if !c.When.Equal(test.want) {
+				t.Errorf("expected When to be '%s', got '%s'", test.want, c.When)
+			}
+		})
+	}
+}
+
+func TestTimeLayoutErrors(t *testing.T) {
+	var c layoutSpec
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.FlagSet().SetOutput(&strings.Builder{})
+	err = p.Parse([]string{"--when=04/05/2022"})
+	if err == nil {
+		t.Fatal("expected an error for a value matching no layout")
+	}
+	if !strings.Contains(err.Error(), "'2006-01-02', '2006-01-02T15:04:05Z07:00'") {
+		t.Errorf("expected the error to list the layouts, got '%s'", err)
+	}
+
+	var misspelt struct {
+		When time.Time `layout:"YYYY-MM-DD"`
+	}
+	if _, err := New(&misspelt, WithDefault, WithViper(viper.New())); err == nil {
+		t.Error("expected an error for a layout without reference time elements")
+	}
+}
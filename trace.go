@@ -0,0 +1,87 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import "fmt"
+
+// TracePhase identifies the phase of processing a field that generated a
+// trace event
+type TracePhase int
+
+// Defines the phases of processing a field
+const (
+	// TraceNaming the key, environment variable, and flag names of the
+	// field have been computed
+	TraceNaming TracePhase = iota
+
+	// TraceDefaultParse the default value of the field has been parsed
+	TraceDefaultParse
+
+	// TraceBind the field has been bound to viper and the flag set
+	TraceBind
+)
+
+// String returns the name of the phase
+func (p TracePhase) String() string {
+	switch p {
+	case TraceNaming:
+		return "naming"
+	case TraceDefaultParse:
+		return "default-parse"
+	case TraceBind:
+		return "bind"
+	}
+	return fmt.Sprintf("TracePhase(%d)", int(p))
+}
+
+// TraceEvent describes a step in the processing of a single field of a
+// configuration specification
+type TraceEvent struct {
+	// Field the names of the struct fields leading to, and including, the
+	// field
+	Field []string
+
+	// Phase the phase of processing that generated the event
+	Phase TracePhase
+
+	// Key, Env, Long, and Short the computed names of the field, empty
+	// when the field has no environment variable, flag, or short flag
+	Key   string
+	Env   string
+	Long  string
+	Short string
+
+	// Default the parsed default value, set from the TraceDefaultParse
+	// phase onwards
+	Default interface{}
+}
+
+// trace sends an event for the field to the configured trace function, if
+// any
+func (o ProcessingOptions) trace(f *field, phase TracePhase, def interface{}) {
+	if o.Trace == nil {
+		return
+	}
+	o.Trace(TraceEvent{
+		Field:   f.path(),
+		Phase:   phase,
+		Key:     f.key,
+		Env:     f.env,
+		Long:    f.long,
+		Short:   f.short,
+		Default: f.redact(def),
+	})
+}
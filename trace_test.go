@@ -0,0 +1,65 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestTrace(t *testing.T) {
+	var c struct {
+		Server struct {
+			Port int `default:"80" short:"p"`
+		}
+		Password string `default:"hunter2" secret:"true"`
+	}
+	var events []TraceEvent
+	_, err := New(&c, WithDefault, WithPrefix("APP"), WithViper(viper.New()), WithTrace(func(event TraceEvent) {
+		events = append(events, event)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every field is named before any is bound
+	want := []TraceEvent{
+		{Field: []string{"Server", "Port"}, Phase: TraceNaming, Key: "server.port", Env: "APP_SERVER_PORT", Long: "server-port", Short: "p"},
+		{Field: []string{"Password"}, Phase: TraceNaming, Key: "password", Env: "APP_PASSWORD", Long: "password"},
+		{Field: []string{"Server", "Port"}, Phase: TraceDefaultParse, Key: "server.port", Env: "APP_SERVER_PORT", Long: "server-port", Short: "p", Default: 80},
+		{Field: []string{"Server", "Port"}, Phase: TraceBind, Key: "server.port", Env: "APP_SERVER_PORT", Long: "server-port", Short: "p", Default: 80},
+		{Field: []string{"Password"}, Phase: TraceDefaultParse, Key: "password", Env: "APP_PASSWORD", Long: "password", Default: "****"},
+		{Field: []string{"Password"}, Phase: TraceBind, Key: "password", Env: "APP_PASSWORD", Long: "password", Default: "****"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("expected the events:\n%+v\ngot:\n%+v", want, events)
+	}
+}
+
+func TestTracePhaseString(t *testing.T) {
+	for phase, want := range map[TracePhase]string{
+		TraceNaming:       "naming",
+		TraceDefaultParse: "default-parse",
+		TraceBind:         "bind",
+		TracePhase(9):     "TracePhase(9)",
+	} {
+		if got := phase.String(); got != want {
+			t.Errorf("expected '%s', got '%s'", want, got)
+		}
+	}
+}
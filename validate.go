@@ -0,0 +1,263 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ValidatorFunc validates a resolved configuration value, returning an
+// error if the value is not valid
+type ValidatorFunc func(value interface{}) error
+
+var (
+	validatorsMu sync.RWMutex
+	validators   = map[string]ValidatorFunc{}
+)
+
+// RegisterValidator registers a named validator that can be referenced
+// from a `validate` tag as `validate:"@name"`. Registering a validator with
+// the same name as an existing validator replaces it.
+func RegisterValidator(name string, fn ValidatorFunc) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[name] = fn
+}
+
+// lookupValidator returns the validator registered with the given name
+func lookupValidator(name string) (ValidatorFunc, bool) {
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+	fn, ok := validators[name]
+	return fn, ok
+}
+
+// Errors aggregates multiple errors, such as those found when validating a
+// configuration, into a single error
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the aggregated errors
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// errorOrNil returns nil if there are no errors, else the errors
+func (e Errors) errorOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// validateField runs the validators referenced by the field's `validate`
+// tag against the given value. All the validators are run and their errors
+// are returned.
+func validateField(f *field, value reflect.Value) Errors {
+	tag := f.tag.Get("validate")
+	if tag == "" {
+		return nil
+	}
+
+	var errs Errors
+	for _, ref := range strings.Split(tag, ",") {
+		ref = strings.TrimSpace(ref)
+		if !strings.HasPrefix(ref, "@") {
+			errs = append(errs, configErrorf(f, "validate", "invalid validator reference '%s', must be of the form '@name'", ref))
+			continue
+		}
+		name := strings.TrimPrefix(ref, "@")
+		fn, ok := lookupValidator(name)
+		if !ok {
+			errs = append(errs, configErrorf(f, "validate", "unknown validator '%s'", name))
+			continue
+		}
+		if err := fn(value.Interface()); err != nil {
+			errs = append(errs, configErrorf(f, "validate", "validator '%s': %w", name, err))
+		}
+	}
+	return errs
+}
+
+// durationRange returns the inclusive range of a duration field, as
+// specified by its `min` and `max` tags, and whether each bound was set
+func (f *field) durationRange() (min, max time.Duration, hasMin, hasMax bool, err error) {
+	if tag := f.tag.Get("min"); tag != "" {
+		parsed, err := f.parse(tag)
+		if err != nil {
+			return 0, 0, false, false, fmt.Errorf("invalid min '%s': %w", tag, err)
+		}
+		min, hasMin = parsed.(time.Duration), true
+	}
+	if tag := f.tag.Get("max"); tag != "" {
+		parsed, err := f.parse(tag)
+		if err != nil {
+			return 0, 0, false, false, fmt.Errorf("invalid max '%s': %w", tag, err)
+		}
+		max, hasMax = parsed.(time.Duration), true
+	}
+	return min, max, hasMin, hasMax, nil
+}
+
+// validateDurationRange checks that the value of a duration field is
+// within the range specified by its `min` and `max` tags
+func validateDurationRange(f *field, value reflect.Value) error {
+	if f.typ != durationType {
+		return nil
+	}
+	min, max, hasMin, hasMax, err := f.durationRange()
+	if err != nil {
+		return configError(f, "", err)
+	}
+	d := time.Duration(value.Int())
+	if hasMin && d < min {
+		return configErrorf(f, "min", "value '%s' is less than the minimum '%s'", d, min)
+	}
+	if hasMax && d > max {
+		return configErrorf(f, "max", "value '%s' is greater than the maximum '%s'", d, max)
+	}
+	return nil
+}
+
+// validateExclusiveBools checks that at most one of the boolean fields in
+// each `exclusiveBool` group resolved to true. Resolved values are checked,
+// so a default of true counts the same as one set by a flag or environment
+// variable.
+func validateExclusiveBools(fields []*field, specElem reflect.Value) Errors {
+	var errs Errors
+	var groups []string
+	set := map[string][]string{}
+	for _, f := range fields {
+		group := f.tag.Get("exclusiveBool")
+		if group == "" {
+			continue
+		}
+		if f.typ.Kind() != reflect.Bool {
+			errs = append(errs, configErrorf(f, "exclusiveBool", "exclusiveBool is only valid for boolean fields"))
+			continue
+		}
+		if _, ok := set[group]; !ok {
+			groups = append(groups, group)
+			set[group] = nil
+		}
+		if value, ok := f.value(specElem); ok && value.Bool() {
+			set[group] = append(set[group], f.name)
+		}
+	}
+
+	for _, group := range groups {
+		if names := set[group]; len(names) > 1 {
+			errs = append(errs, fmt.Errorf("at most one of the fields in exclusive group '%s' may be true, found '%s'",
+				group, strings.Join(names, "', '")))
+		}
+	}
+	return errs
+}
+
+// describeSource names where the given source provides the value of the
+// field, e.g. the environment variable 'MYAPP_PORT'
+func describeSource(f *field, src Source) string {
+	switch src {
+	case FlagSource:
+		return fmt.Sprintf("flag '--%s'", f.long)
+	case EnvSource:
+		return fmt.Sprintf("environment variable '%s'", f.env)
+	case FileSource:
+		return fmt.Sprintf("configuration key '%s'", f.read)
+	case ArgSource:
+		return "positional argument"
+	}
+	return "default"
+}
+
+// Validate checks, without setting any members, that the value provided by
+// every source of each field can be converted to the field's type, rather
+// than only the value of the source that takes precedence, e.g. that an
+// environment variable for an int field is numeric even when it is
+// overridden by a flag. The resolved values are then checked against the
+// `required`, `requiredEnv`, `min`, `max`, and `choices` tags as by Apply. This can be
+// called once the flags have been parsed to report a misconfiguration at
+// startup, before the configuration is used. The errors for all the fields
+// are returned as Errors.
+func (p *Processor) Validate() error {
+	file, err := p.fileConfig()
+	if err != nil {
+		return err
+	}
+	resolve, err := p.resolver()
+	if err != nil {
+		return err
+	}
+
+	var errs Errors
+	for _, f := range p.fields {
+		if f.isRaw() || !f.parseable() {
+			continue
+		}
+
+		var sourceErrs Errors
+		for _, src := range allSources {
+			raw, ok, err := p.lookup(f, src, file)
+			if err == nil && ok {
+				raw, err = f.decrypt(raw)
+			}
+			if err != nil {
+				sourceErrs = append(sourceErrs, configErrorf(f, "", "%s: %w", describeSource(f, src), err))
+				continue
+			}
+			if !ok {
+				continue
+			}
+			if _, err := f.decode(raw); err != nil && f.isSecret() {
+				sourceErrs = append(sourceErrs, configErrorf(f, "", "%s: cannot convert value to %s", describeSource(f, src), f.typ))
+			} else if err != nil {
+				sourceErrs = append(sourceErrs, configErrorf(f, "", "%s: cannot convert '%v' to %s: %w", describeSource(f, src), raw, f.typ, err))
+			}
+		}
+		if len(sourceErrs) > 0 {
+			errs = append(errs, sourceErrs...)
+			continue
+		}
+
+		// A pointer field without a value is left nil by Apply, so there
+		// is no value to validate
+		if f.pointer && !p.isSet(f) && f.def == "" {
+			continue
+		}
+		target := *f
+		target.pointer = false
+		value := reflect.New(f.typ).Elem()
+		if err := p.applyField(resolve, &target, value); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, validateConstraints(f, value)...)
+	}
+	errs = append(errs, validateRequired(p.fields, p.isSet)...)
+	errs = append(errs, p.validateRequiredEnv()...)
+	return errs.errorOrNil()
+}
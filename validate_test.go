@@ -0,0 +1,219 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func init() {
+	RegisterValidator("test-even", func(value interface{}) error {
+		if value.(int)%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+	RegisterValidator("test-positive", func(value interface{}) error {
+		if value.(int) <= 0 {
+			return errors.New("must be positive")
+		}
+		return nil
+	})
+}
+
+func applyArgs(t *testing.T, spec interface{}, args ...string) error {
+	t.Helper()
+	p, err := New(spec, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return p.Apply()
+}
+
+func TestValidators(t *testing.T) {
+	type spec struct {
+		Count int `default:"2" validate:"@test-even, @test-positive"`
+	}
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{},
+		{args: []string{"--count=4"}},
+		{args: []string{"--count=3"}, want: []string{"validator 'test-even': must be even"}},
+		{args: []string{"--count=-3"}, want: []string{"must be even", "must be positive"}},
+	}
+	for _, test := range tests {
+		var c spec
+		err := applyArgs(t, &c, test.args...)
+		if len(test.want) == 0 {
+			if err != nil {
+				t.Errorf("%v: unexpected error '%s'", test.args, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%v: expected an error", test.args)
+			continue
+		}
+		for _, want := range test.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%v: expected the error to contain '%s', got '%s'", test.args, want, err)
+			}
+		}
+		var cerr *ConfigError
+		if !errors.As(firstError(err), &cerr) || cerr.Field != "Count" || cerr.Tag != "validate" {
+			t.Errorf("%v: expected a ConfigError for the validate tag of Count, got %#v", test.args, err)
+		}
+	}
+}
+
+func TestValidatorReferences(t *testing.T) {
+	var unknown struct {
+		Count int `validate:"@test-missing"`
+	}
+	if err := applyArgs(t, &unknown); err == nil || !strings.Contains(err.Error(), "unknown validator 'test-missing'") {
+		t.Errorf("expected an unknown validator error, got '%v'", err)
+	}
+
+	var malformed struct {
+		Count int `validate:"test-even"`
+	}
+	if err := applyArgs(t, &malformed); err == nil || !strings.Contains(err.Error(), "must be of the form '@name'") {
+		t.Errorf("expected an invalid reference error, got '%v'", err)
+	}
+}
+
+// firstError returns the first of aggregated Errors, or the error itself
+func firstError(err error) error {
+	if errs, ok := err.(Errors); ok && len(errs) > 0 {
+		return errs[0]
+	}
+	return err
+}
+
+func TestExclusiveBools(t *testing.T) {
+	type spec struct {
+		JSON  bool `exclusiveBool:"format"`
+		YAML  bool `exclusiveBool:"format"`
+		Table bool `exclusiveBool:"format" default:"true"`
+		Quiet bool `exclusiveBool:"noise"`
+		Loud  bool `exclusiveBool:"noise"`
+	}
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{},
+		{args: []string{"--table=false", "--json"}},
+		{args: []string{"--json"}, want: "exclusive group 'format' may be true, found 'JSON', 'Table'"},
+		{args: []string{"--table=false", "--json", "--yaml", "--quiet", "--loud"}, want: "exclusive group 'noise' may be true, found 'Quiet', 'Loud'"},
+	}
+	for _, test := range tests {
+		var c spec
+		err := applyArgs(t, &c, test.args...)
+		switch {
+		case test.want == "" && err != nil:
+			t.Errorf("%v: unexpected error '%s'", test.args, err)
+		case test.want != "" && (err == nil || !strings.Contains(err.Error(), test.want)):
+			t.Errorf("%v: expected the error to contain '%s', got '%v'", test.args, test.want, err)
+		}
+	}
+
+	var notBool struct {
+		Level int `exclusiveBool:"format"`
+	}
+	if err := applyArgs(t, &notBool); err == nil || !strings.Contains(err.Error(), "only valid for boolean fields") {
+		t.Errorf("expected an error for a non boolean field, got '%v'", err)
+	}
+}
+
+func TestProcessorValidate(t *testing.T) {
+	os.Setenv("CHECK_PORT", "http")
+	defer os.Unsetenv("CHECK_PORT")
+	os.Setenv("CHECK_PIN", "hunter2")
+	defer os.Unsetenv("CHECK_PIN")
+
+	var c struct {
+		Port    int `default:"80"`
+		Retries int `max:"5"`
+		Timeout int
+		Pin     int    `secret:"true"`
+		Name    string `required:"true"`
+	}
+	p, err := New(&c, WithDefault, WithPrefix("CHECK"), WithViper(viper.New()),
+		WithConfigReader(strings.NewReader("timeout: soon"), "yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--port=8080", "--retries=9", "--pin=1234"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.Validate()
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("expected Errors, got '%v'", err)
+	}
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.Error()
+	}
+	all := strings.Join(messages, "\n")
+	for _, want := range []string{
+		"field 'Port': environment variable 'CHECK_PORT': cannot convert 'http' to int",
+		"field 'Retries': value '9' is greater than the maximum '5'",
+		"field 'Timeout': configuration key 'timeout': cannot convert 'soon' to int",
+		"field 'Pin': environment variable 'CHECK_PIN': cannot convert value to int",
+		"field 'Name'",
+	} {
+		if !strings.Contains(all, want) {
+			t.Errorf("expected an error containing '%s', got:\n%s", want, all)
+		}
+	}
+	if strings.Contains(all, "hunter2") {
+		t.Errorf("expected the secret value not to be included, got:\n%s", all)
+	}
+	if c.Port != 0 || c.Retries != 0 {
+		t.Errorf("expected no members to be set, got Port %d and Retries %d", c.Port, c.Retries)
+	}
+}
+
+func TestProcessorValidateClean(t *testing.T) {
+	var c struct {
+		Port int `default:"80" min:"1"`
+		Host string
+	}
+	p, err := New(&c, WithDefault, WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--host=example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("expected no errors, got '%v'", err)
+	}
+}
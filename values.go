@@ -0,0 +1,364 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// urlValue implements the pflag.Value interface for a url.URL
+type urlValue url.URL
+
+func newURLValue(val url.URL) *urlValue {
+	u := urlValue(val)
+	return &u
+}
+
+func (u *urlValue) String() string {
+	return (*url.URL)(u).String()
+}
+
+func (u *urlValue) Set(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	*u = urlValue(*parsed)
+	return nil
+}
+
+func (u *urlValue) Type() string {
+	return "url"
+}
+
+// parseIPMask parses an IPv4 mask in dotted decimal, e.g. `255.255.255.0`,
+// or hexadecimal, e.g. `ffffff00`, form, as accepted by pflag, or as a
+// prefix length, e.g. `/24`. The mask must be canonical, i.e. its ones must
+// be followed by its zeros.
+func parseIPMask(value string) (net.IPMask, error) {
+	var mask net.IPMask
+	if strings.HasPrefix(value, "/") {
+		if ones, err := strconv.Atoi(value[1:]); err == nil && ones >= 0 && ones <= 32 {
+			mask = net.CIDRMask(ones, 32)
+		}
+	} else {
+		mask = pflag.ParseIPv4Mask(value)
+	}
+	if mask == nil {
+		return nil, fmt.Errorf("invalid IP mask '%s'", value)
+	}
+	if _, bits := mask.Size(); bits == 0 {
+		return nil, fmt.Errorf("invalid IP mask '%s', must be canonical", value)
+	}
+	return mask, nil
+}
+
+// formatIPMask renders an IP mask in dotted decimal form, rather than the
+// hexadecimal form rendered by net.IPMask
+func formatIPMask(mask net.IPMask) string {
+	if len(mask) == 0 {
+		return ""
+	}
+	return net.IP(mask).String()
+}
+
+var extendedDurationRegexp = regexp.MustCompile(`([0-9]*\.?[0-9]+)([dw])`)
+
+// parseExtendedDuration parses a duration that may, in addition to the
+// units accepted by time.ParseDuration, use the units 'd', 24 hours, and
+// 'w', 7 days, e.g. "2w" or "1d12h"
+func parseExtendedDuration(value string) (time.Duration, error) {
+	var convErr error
+	converted := extendedDurationRegexp.ReplaceAllStringFunc(value, func(match string) string {
+		parts := extendedDurationRegexp.FindStringSubmatch(match)
+		n, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			convErr = err
+			return match
+		}
+		hours := n * 24
+		if parts[2] == "w" {
+			hours *= 7
+		}
+		return strconv.FormatFloat(hours, 'f', -1, 64) + "h"
+	})
+	if convErr != nil {
+		return 0, fmt.Errorf("invalid duration '%s'", value)
+	}
+	d, err := time.ParseDuration(converted)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration '%s'", value)
+	}
+	return d, nil
+}
+
+// durationUnits the units that can be specified by the `unit` tag of a
+// duration field
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// durationValue implements the pflag.Value interface for a time.Duration
+// parsed by the given function, e.g. one that accepts the extended units of
+// parseExtendedDuration
+type durationValue struct {
+	value time.Duration
+	parse func(string) (time.Duration, error)
+}
+
+func newDurationValue(val time.Duration, parse func(string) (time.Duration, error)) *durationValue {
+	return &durationValue{value: val, parse: parse}
+}
+
+func (d *durationValue) String() string {
+	return d.value.String()
+}
+
+func (d *durationValue) Set(value string) error {
+	parsed, err := d.parse(value)
+	if err != nil {
+		return err
+	}
+	d.value = parsed
+	return nil
+}
+
+func (d *durationValue) Type() string {
+	return "duration"
+}
+
+// durationSliceValue implements the pflag.Value interface for a
+// []time.Duration whose elements are parsed by the given function. As with
+// pflag's slice values, the first value set replaces the default and
+// subsequent values are appended.
+type durationSliceValue struct {
+	value   []time.Duration
+	parse   func(string) (time.Duration, error)
+	changed bool
+}
+
+func newDurationSliceValue(val []time.Duration, parse func(string) (time.Duration, error)) *durationSliceValue {
+	return &durationSliceValue{value: val, parse: parse}
+}
+
+func (d *durationSliceValue) String() string {
+	parts := make([]string, len(d.value))
+	for i, v := range d.value {
+		parts[i] = v.String()
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func (d *durationSliceValue) Set(value string) error {
+	var list []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		parsed, err := d.parse(strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		list = append(list, parsed)
+	}
+	if d.changed {
+		d.value = append(d.value, list...)
+	} else {
+		d.value = list
+		d.changed = true
+	}
+	return nil
+}
+
+func (d *durationSliceValue) Type() string {
+	return "durationSlice"
+}
+
+// stringSliceMapValue implements the pflag.Value interface for a map of
+// strings to lists of strings, parsing values as a map field. As with
+// pflag's slice values, the first value set replaces the default while the
+// entries of subsequent values are combined with those already set, so the
+// flag can be repeated, e.g. `--header accept=a,b --header x=1`.
+type stringSliceMapValue struct {
+	f       *field
+	value   map[string][]string
+	changed bool
+}
+
+func newStringSliceMapValue(f *field, val map[string][]string) *stringSliceMapValue {
+	return &stringSliceMapValue{f: f, value: val}
+}
+
+func (m *stringSliceMapValue) String() string {
+	return formatValue(m.f, reflect.ValueOf(m.value))
+}
+
+func (m *stringSliceMapValue) Set(value string) error {
+	parsed, err := m.f.parseMap(value)
+	if err != nil {
+		return err
+	}
+	if !m.changed {
+		m.value = map[string][]string{}
+		m.changed = true
+	}
+	for k, v := range parsed.(map[string][]string) {
+		m.value[k] = append(m.value[k], v...)
+	}
+	return nil
+}
+
+func (m *stringSliceMapValue) Type() string {
+	_, kvSep := m.f.mapSeparators()
+	return "key" + kvSep + "values"
+}
+
+// timeLayouts returns the layouts specified by a layout tag, separated by
+// '|', defaulting to RFC3339 when no layout is specified
+func timeLayouts(tag string) []string {
+	if tag == "" {
+		return []string{time.RFC3339}
+	}
+	return strings.Split(tag, "|")
+}
+
+// checkLayouts returns an error if any of the layouts contains no elements
+// of the reference time, e.g. a misspelt layout such as `YYYY-MM-DD`, as
+// such a layout only matches itself
+func checkLayouts(layouts []string) error {
+	for _, layout := range layouts {
+		if time.Unix(0, 0).UTC().Format(layout) == layout {
+			return fmt.Errorf("invalid layout '%s', must be expressed using the reference time 'Mon Jan 2 15:04:05 MST 2006'", layout)
+		}
+	}
+	return nil
+}
+
+// parseTime parses the value using each of the given layouts in order,
+// returning the first successful result
+func parseTime(value string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse '%s' as time using layouts '%s'",
+		value, strings.Join(layouts, "', '"))
+}
+
+// timeValue implements the pflag.Value interface for a time.Time, parsing
+// values with the given layouts and formatting with the first that
+// preserves the value, so that viper, which reads the flag's string, does
+// not truncate a time parsed with a more precise layout
+type timeValue struct {
+	value   time.Time
+	layouts []string
+}
+
+func newTimeValue(val time.Time, layouts []string) *timeValue {
+	return &timeValue{value: val, layouts: layouts}
+}
+
+func (t *timeValue) String() string {
+	if t.value.IsZero() {
+		return ""
+	}
+	for _, layout := range t.layouts {
+		formatted := t.value.Format(layout)
+		if parsed, err := time.Parse(layout, formatted); err == nil && parsed.Equal(t.value) {
+			return formatted
+		}
+	}
+	return t.value.Format(t.layouts[0])
+}
+
+func (t *timeValue) Set(value string) error {
+	parsed, err := parseTime(value, t.layouts)
+	if err != nil {
+		return err
+	}
+	t.value = parsed
+	return nil
+}
+
+func (t *timeValue) Type() string {
+	return "time"
+}
+
+// typeNameValue wraps a pflag.Value overriding the type name displayed as
+// the value placeholder in the usage
+type typeNameValue struct {
+	pflag.Value
+	name string
+}
+
+func (t *typeNameValue) Type() string {
+	return t.name
+}
+
+// trimmedListValue wraps the pflag.Value of a slice flag, removing the
+// whitespace around each element of a value before it is set, e.g.
+// `--ports "80, 443"`, as pflag's slice values do not
+type trimmedListValue struct {
+	pflag.Value
+}
+
+func (t *trimmedListValue) Set(value string) error {
+	parts := splitList(value)
+	for i, part := range parts {
+		parts[i] = quoteListElement(part)
+	}
+	return t.Value.Set(strings.Join(parts, ","))
+}
+
+// complexValue implements the pflag.Value interface for a complex number
+// with the given number of bits, i.e. a complex64 or complex128
+type complexValue struct {
+	value complex128
+	bits  int
+}
+
+func newComplexValue(val complex128, bits int) *complexValue {
+	return &complexValue{value: val, bits: bits}
+}
+
+func (c *complexValue) String() string {
+	return formatComplex(c.value)
+}
+
+func (c *complexValue) Set(value string) error {
+	parsed, err := parseComplex(value, c.bits)
+	if err != nil {
+		return err
+	}
+	c.value = parsed
+	return nil
+}
+
+func (c *complexValue) Type() string {
+	return "complex" + strconv.Itoa(c.bits)
+}
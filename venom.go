@@ -22,12 +22,17 @@ package venom
 import (
 	"errors"
 	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
 	"path"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -47,14 +52,77 @@ const (
 	// None represents the zero value (i.e that no options are set)
 	None Flags = 0x0
 
-	// WithEnv specifies that the parser should automatically generate a environment variable for options
-	WithEnv = 0x1
+	// GenerateEnv specifies that the parser should automatically generate a environment variable for options, see WithEnv
+	GenerateEnv Flags = 0x1
 
-	// WithFlag specifies that the parser should automatically generate a pflag for options
-	WithFlag = 0x2
+	// GenerateFlag specifies that the parser should automatically generate a pflag for options, see WithFlag
+	GenerateFlag Flags = 0x2
+
+	// WithEmptyEnvIsTrue specifies that a boolean option whose environment variable is set, but empty, should be considered true
+	WithEmptyEnvIsTrue Flags = 0x4
+
+	// OnlyTagged specifies that the parser should only process fields that have at least one configuration tag
+	OnlyTagged Flags = 0x8
+
+	// WithDefaultRoundTripCheck specifies that each default value should be rendered back to a string and a warning logged when it differs from the default tag
+	WithDefaultRoundTripCheck Flags = 0x10
+
+	// WithRequireHelp specifies that an error should be returned if any generated flag does not have a help tag
+	WithRequireHelp Flags = 0x20
+
+	// WithExtendedDurations specifies that duration values may additionally use the units 'd', days, and 'w', weeks
+	WithExtendedDurations Flags = 0x40
+
+	// WithoutAutoHelp specifies that -h and --help should not be treated as a request for help when not otherwise defined
+	WithoutAutoHelp Flags = 0x80
+
+	// WithRequireBinding specifies that an error should be returned if any field has no environment variable, flag, or explicit key
+	WithRequireBinding Flags = 0x100
+
+	// WithFlatKeys specifies that the viper keys of nested fields should not be prefixed by the enclosing field and that fields whose keys collide are an error
+	WithFlatKeys Flags = 0x200
+
+	// WithCaseSensitiveKeys specifies that the case of the viper keys should be preserved, rather than lower cased as viper does, in the keys reported by venom
+	WithCaseSensitiveKeys Flags = 0x400
+
+	// WithDebug specifies that debug messages describing the processing of each field should be logged, to stderr if no logger is set
+	WithDebug Flags = 0x800
+
+	// WithSortedOutput specifies that generated lists, such as the assignments returned by ExportResolved, should be sorted alphabetically rather than in declaration order
+	WithSortedOutput Flags = 0x1000
+
+	// WithReuseExistingFlags specifies that a field whose flag is already defined in the flag set should be bound to the existing flag rather than redefining it
+	WithReuseExistingFlags Flags = 0x2000
+
+	// WithProgramPrefix specifies that, when no prefix is given, the environment variable prefix should be derived from the program name, e.g. MY_APP for my-app
+	WithProgramPrefix Flags = 0x4000
+
+	// WithSanitizedEnv specifies that invalid characters in generated environment variable names should be replaced by '_', rather than being an error
+	WithSanitizedEnv Flags = 0x8000
+
+	// WithoutViper specifies that only flags should be registered, with their defaults, and nothing bound to viper, e.g. for flags only programs; it is not supported by New
+	WithoutViper Flags = 0x10000
+
+	// WithHomeExpansion specifies that a leading '~' in the values, including the default, of string fields tagged `path` should be expanded to the home directory
+	WithHomeExpansion Flags = 0x20000
+
+	// WithLenientNumbers specifies that numeric values may be surrounded by whitespace and integers written in floating point notation, e.g. a quoted number templated into a configuration file
+	WithLenientNumbers Flags = 0x40000
+
+	// WithUnitHints specifies that the help of duration flags should end with the accepted units, e.g. `(units: ns,us,ms,s,m,h)`
+	WithUnitHints Flags = 0x80000
+
+	// WithStrictRequired specifies that an error should be returned if any required field does not have a help tag and an environment variable
+	WithStrictRequired Flags = 0x100000
+
+	// WithStrictSelector specifies that an error should be returned if the tag selector does not select any field
+	WithStrictSelector Flags = 0x200000
+
+	// WithEnvInUsage specifies that the help of each flag should end with its environment variable, e.g. `[env: MYAPP_PORT]`, unless the field is a secret
+	WithEnvInUsage Flags = 0x400000
 
 	// DefaultProcessingOptions  represents a useful set of default options for the parser
-	WithDefault = WithEnv | WithFlag
+	WithDefault Flags = GenerateEnv | GenerateFlag
 )
 
 // ProcessingOption provides a mechanism to customize how the long and env
@@ -63,6 +131,73 @@ type ProcessingOptions struct {
 	Flags         Flags
 	LongSeparator string
 	EnvSeparator  string
+	KeyDelimiter  string
+	KeyNamespace  string
+	KeyReplacer   *strings.Replacer
+	Logger        Logger
+	OnFieldError  func(fieldPath []string, err error)
+	Trace         func(event TraceEvent)
+	DefaultsFunc  func(fieldPath []string) (string, bool)
+
+	// DefaultParseFallback how a field whose default cannot be parsed is
+	// processed, by default an error
+	DefaultParseFallback Fallback
+
+	// Profile the active profile, e.g. "dev", under which fields with a
+	// `profile` tag that lists it are processed
+	Profile string
+
+	// TagSelectorKey and TagSelectorValue, when the key is set, select the
+	// fields that are processed, i.e. those, or those within a struct,
+	// whose tag with the key has the value, e.g. `audience:"public"`
+	TagSelectorKey   string
+	TagSelectorValue string
+
+	// ValidationMode when the validations that do not require the resolved
+	// values, such as of the defaults, are run, by default when applied
+	ValidationMode ValidationMode
+}
+
+// keyDelimiter returns the delimiter used to join the viper keys of nested
+// fields, which defaults to the viper default of "."
+func (o ProcessingOptions) keyDelimiter() string {
+	if o.KeyDelimiter == "" {
+		return "."
+	}
+	return o.KeyDelimiter
+}
+
+// fieldError reports the error processing the field to the configured
+// callback, if any
+func (o ProcessingOptions) fieldError(f *field, err error) {
+	if o.OnFieldError != nil {
+		o.OnFieldError(f.path(), err)
+	}
+}
+
+// Logger receives the warnings generated while processing a configuration
+// specification
+type Logger func(format string, args ...interface{})
+
+// logf logs the message using the configured logger, if any
+func (o ProcessingOptions) logf(format string, args ...interface{}) {
+	if o.Logger != nil {
+		o.Logger(format, args...)
+	}
+}
+
+// debugf logs the debug message when WithDebug is set, using the configured
+// logger or, if there is none, writing it to stderr so that it does not mix
+// with the program's output
+func (o ProcessingOptions) debugf(format string, args ...interface{}) {
+	if o.Flags&WithDebug == 0 {
+		return
+	}
+	if o.Logger == nil {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+		return
+	}
+	o.Logger(format, args...)
 }
 
 // DefaultOption some sane default options
@@ -72,6 +207,54 @@ var DefaultOptions = ProcessingOptions{
 	EnvSeparator:  "_",
 }
 
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	ipType       = reflect.TypeOf(net.IP{})
+	ipMaskType   = reflect.TypeOf(net.IPMask{})
+	urlType      = reflect.TypeOf(url.URL{})
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// tagNames the structure tags that are used to configure a field
+var tagNames = []string{
+	"long", "l",
+	"short", "s",
+	"default", "d",
+	"defaultLinux", "defaultDarwin", "defaultWindows",
+	"env", "e",
+	"envLegacy", "envLegacyTransform",
+	"help", "h",
+	"layout",
+	"path",
+	"unit",
+	"encoding",
+	"positional",
+	"raw",
+	"required", "requiredEnv",
+	"presence",
+	"secret", "secretFile", "decrypt",
+	"group",
+	"profile",
+	"name", "prefix",
+	"impl",
+	"provides",
+	"deriveFrom", "derive",
+	"pairSeparator", "kvSeparator",
+	"args",
+	"validate",
+	"exclusiveBool",
+	"count",
+	"array",
+	"min", "max",
+	"choices",
+	"countOverflow",
+	"key", "readKey",
+	"deprecated", "deprecatedSince", "removeIn",
+	"aliases",
+	"hidden",
+	"typeName",
+}
+
 var gatherRegexp = regexp.MustCompile("([^A-Z0-9]+|[A-Z0-9]+[^A-Z0-9]+|[A-Z0-9]+)")
 var acronymRegexp = regexp.MustCompile("([A-Z0-9]+)([A-Z0-9][^A-Z0-9]+)")
 
@@ -85,6 +268,17 @@ func isTrue(value string) bool {
 	return false
 }
 
+// hasConfigurationTag returns true if the given structure tag contains at
+// least one of the tags used to configure a field
+func hasConfigurationTag(tag reflect.StructTag) bool {
+	for _, name := range tagNames {
+		if _, ok := tag.Lookup(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // splitIntoWords separates the given value string into "words" separated
 // bu the specified separation character. Separation is accomplished attempting
 // to follow CamelCase format.
@@ -110,236 +304,700 @@ func splitIntoWords(value, sep string) string {
 // adding flags to the specified flagset as well as setting up environment
 // variable configurations options based on the specified processing options.
 func AddConfiguration(flagSet *pflag.FlagSet, configSpecification interface{}, prefix string, options ProcessingOptions, args []string) error {
-	spec := reflect.ValueOf(configSpecification)
+	return AddConfigurationTo(viper.GetViper(), flagSet, configSpecification, prefix, options, args)
+}
 
-	if spec.Kind() != reflect.Ptr || spec.Elem().Kind() != reflect.Struct {
-		return ErrSpecificationType
+// AddConfigurationTo is as AddConfiguration, but binds the configuration
+// to the given viper instance rather than viper's global instance, so that
+// independent components in the same process do not share keys.
+func AddConfigurationTo(v *viper.Viper, flagSet *pflag.FlagSet, configSpecification interface{}, prefix string, options ProcessingOptions, args []string) error {
+	if prefix == "" && options.Flags&WithProgramPrefix != 0 && len(args) > 0 {
+		prefix = programPrefix(args[0])
 	}
+	_, err := addConfiguration(v, flagSet, configSpecification, prefix, options)
+	return err
+}
 
-	specElem := spec.Elem()
-	specType := specElem.Type()
+// programPrefix returns the environment variable prefix derived from the
+// program, i.e. the upper cased base name of the path with each character
+// that is not a letter or digit replaced by '_', e.g. MY_APP for my-app
+func programPrefix(program string) string {
+	return strings.ToUpper(nonIdentifierRegexp.ReplaceAllString(path.Base(program), "_"))
+}
 
-	for i := 0; i < specType.NumField(); i++ {
-		field := specElem.Field(i)
-		fieldType := specType.Field(i)
+// addConfiguration parses the struct tags associated with the
+// configSpecification, binding the results to the given viper instance and
+// returning a description of the processed fields.
+func addConfiguration(v *viper.Viper, flagSet *pflag.FlagSet, configSpecification interface{}, prefix string, options ProcessingOptions) ([]*field, error) {
+	fields, err := describeFields(configSpecification, prefix, options)
+	if err != nil {
+		return nil, err
+	}
 
-		// If the field should not be processed, either implicitly or explicitly, then skip
-		if !field.CanSet() || isTrue(fieldType.Tag.Get("ignored")) {
-			continue
+	if options.Flags&WithRequireHelp != 0 {
+		if err := requireHelp(fields); err != nil {
+			return nil, err
 		}
+	}
 
-		splitEnvName := splitIntoWords(fieldType.Name, options.EnvSeparator)
-		splitLongName := splitIntoWords(fieldType.Name, options.LongSeparator)
+	if options.Flags&WithFlatKeys != 0 {
+		if err := checkKeyCollisions(fields); err != nil {
+			return nil, err
+		}
+	}
 
-		// If an option for an environment variable configuration was set then process
-		envVar := fieldType.Tag.Get("env")
-		if envVar == "" {
-			envVar = fieldType.Tag.Get("e")
+	if options.Flags&WithRequireBinding != 0 {
+		if err := requireBinding(fields); err != nil {
+			return nil, err
 		}
-		if envVar != "" || options.Flags&WithEnv != 0 {
-			if envVar == "" {
-				envVar = strings.ToUpper(splitEnvName)
-			}
+	}
+
+	if options.Flags&WithStrictRequired != 0 {
+		if err := requireStrict(fields); err != nil {
+			return nil, err
 		}
-		if envVar != "" && !strings.HasPrefix(envVar, prefix) {
-			envVar = strings.ToUpper(fmt.Sprintf("%s%s%s", prefix, options.EnvSeparator, envVar))
+	}
+
+	if options.Flags&WithStrictSelector != 0 && options.TagSelectorKey != "" && len(fields) == 0 {
+		return nil, fmt.Errorf("tag selector '%s:\"%s\"' does not select any field", options.TagSelectorKey, options.TagSelectorValue)
+	}
+
+	if err := validatePositionals(fields); err != nil {
+		return nil, err
+	}
+
+	if err := checkFlagCollisions(fields); err != nil {
+		return nil, err
+	}
+
+	if err := checkPrefix(fields, prefix, options); err != nil {
+		return nil, err
+	}
+
+	if err := checkEnvNames(fields); err != nil {
+		return nil, err
+	}
+
+	if err := checkDeprecations(fields); err != nil {
+		return nil, err
+	}
+
+	for _, f := range fields {
+		if err := bindField(v, flagSet, f, options); err != nil {
+			options.fieldError(f, err)
+			return nil, err
 		}
+	}
 
-		// Check for default value specification and if not specified then
-		// use the types zero value
-		defaultAsString := fieldType.Tag.Get("default")
-		if defaultAsString == "" {
-			defaultAsString = fieldType.Tag.Get("d")
+	if options.ValidationMode == ValidateOnBuild {
+		if err := validateOnBuild(fields); err != nil {
+			return nil, err
 		}
-		var defaultValue interface{}
-		var err error
+	}
 
-		if defaultAsString == "" {
-			defaultValue = reflect.Zero(field.Type()).Interface()
+	if options.Flags&WithoutAutoHelp != 0 {
+		suppressHelp(flagSet)
+	}
+	return fields, nil
+}
+
+// suppressHelp prevents pflag from treating -h and --help as a request for
+// help, which it does whenever they are not defined, by defining them as a
+// hidden flag that is ignored. Nothing is defined when a field already
+// defines --help and -h is left to any field that defines it.
+func suppressHelp(flagSet *pflag.FlagSet) {
+	if flagSet.Lookup("help") != nil {
+		return
+	}
+	short := "h"
+	if flagSet.ShorthandLookup(short) != nil {
+		short = ""
+	}
+	flagSet.BoolP("help", short, false, "")
+	_ = flagSet.MarkHidden("help")
+}
+
+// checkRoundTrip logs a warning if the default value, rendered back to a
+// string, differs from the default tag. Floating point values are compared
+// numerically, so that `1.50` and `1.5` are equivalent, and a warning that
+// identifies the loss of precision is logged.
+func checkRoundTrip(f *field, defaultValue interface{}, options ProcessingOptions) {
+	rendered := formatValue(f, reflect.ValueOf(defaultValue))
+	switch f.typ.Kind() {
+	case reflect.Float32, reflect.Float64:
+		specified, ok := new(big.Rat).SetString(f.def)
+		parsed, _ := new(big.Rat).SetString(rendered)
+		if ok && parsed != nil && specified.Cmp(parsed) != 0 {
+			options.logf("field '%s': default '%s' loses precision as %s, parsed as '%s'", f.name, f.def, f.typ.Kind(), rendered)
 		}
+		return
+	}
+	if rendered != f.def {
+		options.logf("field '%s': default '%s' does not round trip, parsed as '%s'", f.name, f.def, rendered)
+	}
+}
 
-		longFlag := fieldType.Tag.Get("long")
-		if longFlag == "" {
-			longFlag = fieldType.Tag.Get("l")
+// requireHelp returns an error listing every field that would generate a
+// flag without help
+func requireHelp(fields []*field) error {
+	var names []string
+	for _, f := range fields {
+		if f.long != "" && f.supported() && f.help == "" {
+			names = append(names, f.name)
 		}
-		if longFlag != "" || options.Flags&WithFlag != 0 {
-			if longFlag == "" {
-				longFlag = strings.ToLower(splitLongName)
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("help must be specified for the flags of fields '%s'", strings.Join(names, "', '"))
+	}
+	return nil
+}
+
+// checkKeyCollisions returns an error if two fields are bound to the same
+// viper key, compared case insensitively as by viper, unless both keys were
+// explicitly specified
+func checkKeyCollisions(fields []*field) error {
+	bound := map[string]*field{}
+	for _, f := range fields {
+		key := strings.ToLower(f.key)
+		if other, ok := bound[key]; ok {
+			_, explicit := f.tag.Lookup("key")
+			_, otherExplicit := other.tag.Lookup("key")
+			if !explicit || !otherExplicit {
+				return fmt.Errorf("fields '%s' and '%s' are both bound to key '%s', use a key tag to disambiguate",
+					other.name, f.name, f.key)
 			}
 		}
+		bound[key] = f
+	}
+	return nil
+}
 
-		shortFlag := fieldType.Tag.Get("short")
-		if shortFlag == "" {
-			shortFlag = fieldType.Tag.Get("s")
+// checkFlagCollisions returns an error if a field's short flag is not a
+// single ASCII character, as required by pflag, or if two fields would define the same long or short
+// flag, e.g. when generated names coincide, as pflag would panic when the
+// flags are registered
+func checkFlagCollisions(fields []*field) error {
+	long := map[string]*field{}
+	short := map[string]*field{}
+	for _, f := range fields {
+		if f.long == "" || !f.supported() {
+			continue
+		}
+		for _, name := range append([]string{f.long}, f.aliasLongs...) {
+			if other, ok := long[name]; ok {
+				return fmt.Errorf("duplicate flag '--%s' defined by fields '%s' and '%s'", name, other.name, f.name)
+			}
+			long[name] = f
 		}
 
-		if envVar != "" {
-			_ = viper.BindEnv(fieldType.Name, envVar)
+		if f.short == "" {
+			continue
+		}
+		if len(f.short) != 1 || f.short[0] > unicode.MaxASCII {
+			return configErrorf(f, "short", "invalid short flag '%s', must be a single ASCII character", f.short)
+		}
+		if other, ok := short[f.short]; ok {
+			return fmt.Errorf("duplicate flag '-%s' defined by fields '%s' and '%s'", f.short, other.name, f.name)
 		}
+		short[f.short] = f
+	}
+	return nil
+}
 
-		if longFlag != "" {
-			help := fieldType.Tag.Get("help")
-			if help == "" {
-				help = fieldType.Tag.Get("h")
-			}
-			switch field.Type().Kind() {
-			case reflect.String:
-				if defaultAsString != "" {
-					defaultValue = defaultAsString
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(string))
-				flagSet.StringP(longFlag, shortFlag, defaultValue.(string), help)
-			case reflect.Bool:
-				if defaultAsString != "" {
-					defaultValue, err = strconv.ParseBool(defaultAsString)
-					if err != nil {
-						return err
-					}
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(bool))
-				flagSet.BoolP(longFlag, shortFlag, defaultValue.(bool), help)
-			case reflect.Int: //, reflect.Int8, reflect.Int16, reflect.Int32:
-				if defaultAsString != "" {
-					defaultValue, err = strconv.ParseInt(defaultAsString, 0, field.Type().Bits())
-					if err != nil {
-						return err
-					}
-					defaultValue = int(defaultValue.(int64))
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(int))
-				flagSet.IntP(longFlag, shortFlag, defaultValue.(int), help)
-			case reflect.Int8: //, reflect.Int8, reflect.Int16, reflect.Int32:
-				if defaultAsString != "" {
-					defaultValue, err = strconv.ParseInt(defaultAsString, 0, field.Type().Bits())
-					if err != nil {
-						return err
-					}
-					defaultValue = int8(defaultValue.(int64))
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(int8))
-				flagSet.Int8P(longFlag, shortFlag, defaultValue.(int8), help)
-			case reflect.Int16: //, reflect.Int8, reflect.Int16, reflect.Int32:
-				if defaultAsString != "" {
-					defaultValue, err = strconv.ParseInt(defaultAsString, 0, field.Type().Bits())
-					if err != nil {
-						return err
-					}
-					defaultValue = int16(defaultValue.(int64))
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(int16))
-				flagSet.Int16P(longFlag, shortFlag, defaultValue.(int16), help)
-			case reflect.Int32: //, reflect.Int8, reflect.Int16, reflect.Int32:
-				if defaultAsString != "" {
-					defaultValue, err = strconv.ParseInt(defaultAsString, 0, field.Type().Bits())
-					if err != nil {
-						return err
-					}
-					defaultValue = int32(defaultValue.(int64))
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(int32))
-				flagSet.Int32P(longFlag, shortFlag, defaultValue.(int32), help)
-			case reflect.Int64:
-				if field.Type().PkgPath() == "time" && field.Type().Name() == "Duration" {
-					if defaultAsString != "" {
-						defaultValue, err = time.ParseDuration(defaultAsString)
-						if err != nil {
-							return err
-						}
-					}
-					viper.SetDefault(fieldType.Name, defaultValue.(time.Duration))
-					flagSet.DurationP(longFlag, shortFlag, defaultValue.(time.Duration), help)
-				} else {
-					if defaultAsString != "" {
-						defaultValue, err = strconv.ParseInt(defaultAsString, 0, field.Type().Bits())
-						if err != nil {
-							return err
-						}
-					}
-					viper.SetDefault(fieldType.Name, defaultValue.(int64))
-					flagSet.Int64P(longFlag, shortFlag, defaultValue.(int64), help)
-				}
-			case reflect.Uint: //, reflect.Int8, reflect.Int16, reflect.Int32:
-				if defaultAsString != "" {
-					defaultValue, err = strconv.ParseUint(defaultAsString, 0, field.Type().Bits())
-					if err != nil {
-						return err
-					}
-					defaultValue = uint(defaultValue.(uint64))
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(uint))
-				flagSet.UintP(longFlag, shortFlag, defaultValue.(uint), help)
-			case reflect.Uint8: //, reflect.Int8, reflect.Int16, reflect.Int32:
-				if defaultAsString != "" {
-					defaultValue, err = strconv.ParseUint(defaultAsString, 0, field.Type().Bits())
-					if err != nil {
-						return err
-					}
-					defaultValue = uint8(defaultValue.(uint64))
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(uint8))
-				flagSet.Uint8P(longFlag, shortFlag, defaultValue.(uint8), help)
-			case reflect.Uint16: //, reflect.Int8, reflect.Int16, reflect.Int32:
-				if defaultAsString != "" {
-					defaultValue, err = strconv.ParseUint(defaultAsString, 0, field.Type().Bits())
-					if err != nil {
-						return err
-					}
-					defaultValue = uint16(defaultValue.(uint64))
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(uint16))
-				flagSet.Uint16P(longFlag, shortFlag, defaultValue.(uint16), help)
-			case reflect.Uint32: //, reflect.Int8, reflect.Int16, reflect.Int32:
-				if defaultAsString != "" {
-					defaultValue, err = strconv.ParseUint(defaultAsString, 0, field.Type().Bits())
-					if err != nil {
-						return err
-					}
-					defaultValue = uint32(defaultValue.(uint64))
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(uint32))
-				flagSet.Uint32P(longFlag, shortFlag, defaultValue.(uint32), help)
-			case reflect.Uint64:
-				if defaultAsString != "" {
-					defaultValue, err = strconv.ParseUint(defaultAsString, 0, field.Type().Bits())
-					if err != nil {
-						return err
-					}
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(uint64))
-				flagSet.Uint64P(longFlag, shortFlag, defaultValue.(uint64), help)
-			case reflect.Float32:
-				if defaultAsString != "" {
-					defaultValue, err = strconv.ParseFloat(defaultAsString, field.Type().Bits())
-					if err != nil {
-						return err
-					}
-					defaultValue = float32(defaultValue.(float64))
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(float32))
-				flagSet.Float32P(longFlag, shortFlag, defaultValue.(float32), help)
-			case reflect.Float64:
-				if defaultAsString != "" {
-					defaultValue, err = strconv.ParseFloat(defaultAsString, field.Type().Bits())
-					if err != nil {
-						return err
-					}
-				}
-				viper.SetDefault(fieldType.Name, defaultValue.(float64))
-				flagSet.Float64P(longFlag, shortFlag, defaultValue.(float64), help)
-			}
-			_ = viper.BindPFlag(fieldType.Name, flagSet.Lookup(longFlag))
+// checkPrefix returns an error if the upper cased prefix is not a valid
+// environment variable name, e.g. `my-app`, as it would make the name of
+// every environment variable invalid, including those specified by env
+// tags. The prefix is sanitized, rather than checked, when WithSanitizedEnv
+// is set, and is not checked if no field has an environment variable.
+func checkPrefix(fields []*field, prefix string, options ProcessingOptions) error {
+	name := strings.TrimSuffix(strings.ToUpper(prefix), options.EnvSeparator)
+	if name == "" || isValidEnv(name) || options.Flags&WithSanitizedEnv != 0 {
+		return nil
+	}
+	for _, f := range fields {
+		if f.env != "" {
+			return fmt.Errorf("invalid prefix '%s', must be a valid environment variable name matching '%s', or use WithSanitizedEnv",
+				prefix, envRegexp)
+		}
+	}
+	return nil
+}
+
+// checkEnvNames returns an error if a generated environment variable name
+// is not a valid POSIX name, e.g. when the prefix starts with a digit, as
+// such a variable cannot be set by a shell. Names specified by an env tag
+// are used as is.
+func checkEnvNames(fields []*field) error {
+	for _, f := range fields {
+		if f.env == "" || tagValue(f.tag, "env", "e") != "" {
+			continue
+		}
+		if !isValidEnv(f.env) {
+			return configErrorf(f, "env", "invalid environment variable name '%s', must match '%s', use an env tag or WithSanitizedEnv",
+				f.env, envRegexp)
+		}
+	}
+	return nil
+}
+
+// checkDeprecations returns an error for the first field with a flag whose
+// deprecated tag is blank. pflag requires a deprecation message, so a blank
+// tag is reported, before any field is bound, rather than silently ignored.
+func checkDeprecations(fields []*field) error {
+	for _, f := range fields {
+		if f.long == "" {
+			continue
+		}
+		if _, ok := f.tag.Lookup("deprecated"); ok && deprecationMessage(f.tag) == "" {
+			return configErrorf(f, "deprecated", "deprecated requires a message, e.g. 'use --new-flag instead'")
+		}
+	}
+	return nil
+}
+
+// requireBinding returns an error listing every field that has no
+// environment variable, flag, or explicit key and so cannot be configured
+func requireBinding(fields []*field) error {
+	var names []string
+	for _, f := range fields {
+		hasFlag := f.long != "" && f.supported()
+		if _, hasKey := f.tag.Lookup("key"); f.env == "" && !hasFlag && !hasKey {
+			names = append(names, f.name)
+		}
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("fields '%s' have no environment variable, flag, or key and cannot be configured, use the ignored tag if intended",
+			strings.Join(names, "', '"))
+	}
+	return nil
+}
+
+// bindField binds the environment variable and flag for a single field to
+// the given viper instance and flag set. When WithoutViper is set only the
+// flag is registered and the viper instance is not used.
+func bindField(v *viper.Viper, flagSet *pflag.FlagSet, f *field, options ProcessingOptions) error {
+	options.debugf("Processing field '%s'", f.name)
+	bind := options.Flags&WithoutViper == 0
+	if f.env != "" && bind {
+		options.debugf("ENV: '%s'", f.env)
+		// Viper uses the first of the environment variables that is set
+		envs := []string{f.key, f.env}
+		if legacy := f.tag.Get("envLegacy"); legacy != "" {
+			envs = append(envs, legacy)
+		}
+		_ = v.BindEnv(append(envs, f.aliasEnvs...)...)
+	}
+
+	// Slices and maps are bound to pflag's slice and map flag types, which
+	// only exist for some element types. Those that can still be parsed,
+	// e.g. []int8, are bound to the environment variable alone, if any.
+	if kind := f.typ.Kind(); (kind == reflect.Slice || kind == reflect.Map) && !f.supported() && f.parseable() && f.env != "" {
+		defaultValue, err := f.defaultValue()
+		if err != nil {
+			return configError(f, "default", err)
+		}
+		if f.long != "" {
+			options.logf("field '%s': type '%s' cannot be bound to a flag, only the environment variable '%s' is bound", f.name, f.typ, f.env)
+		}
+		if bind && ((!f.isRequired() && !f.pointer) || f.def != "") {
+			options.debugf("SETDEF: '%s' = '%v'", f.key, f.redact(defaultValue))
+			v.SetDefault(f.key, defaultValue)
+		}
+		options.trace(f, TraceBind, defaultValue)
+		return nil
+	}
+	if f.typ.Kind() == reflect.Slice && !f.supported() {
+		return configErrorf(f, "", "unsupported slice element type '%s'", f.typ.Elem())
+	}
+	if f.typ.Kind() == reflect.Map && !f.supported() {
+		return configErrorf(f, "", "unsupported map type '%s', must be a map of strings to strings, ints, or lists of strings", f.typ)
+	}
+
+	if isTrue(f.tag.Get("presence")) && f.typ.Kind() != reflect.Bool {
+		return configErrorf(f, "presence", "presence is only valid for boolean fields")
+	}
+
+	if f.isPath() && f.typ.Kind() != reflect.String {
+		return configErrorf(f, "path", "path is only valid for string fields")
+	}
+
+	if err := f.checkSecretFile(); err != nil {
+		return configError(f, "secretFile", err)
+	}
+
+	if err := f.checkRequiredEnv(); err != nil {
+		return configError(f, "requiredEnv", err)
+	}
+
+	if err := f.checkDecrypt(); err != nil {
+		return configError(f, "decrypt", err)
+	}
+
+	if err := f.checkUnit(); err != nil {
+		return configError(f, "unit", err)
+	}
+
+	if err := f.checkEncoding(); err != nil {
+		return configError(f, "encoding", err)
+	}
+
+	if f.long == "" || !f.supported() {
+		options.trace(f, TraceBind, nil)
+		return nil
+	}
+
+	if err := f.checkConstraints(); err != nil {
+		return configError(f, "", err)
+	}
+
+	if f.typ == timeType {
+		if err := checkLayouts(f.layouts()); err != nil {
+			return configError(f, "layout", err)
+		}
+	}
+
+	if f.isArray() && (f.typ.Kind() != reflect.Slice || f.typ.Elem().Kind() != reflect.Bool) {
+		return configErrorf(f, "array", "array is only valid for []bool fields")
+	}
+
+	if f.isCount() {
+		if f.typ.Kind() != reflect.Int {
+			return configErrorf(f, "count", "count is only valid for int fields")
+		}
+		if _, _, err := f.countLimit(); err != nil {
+			return configError(f, "", err)
 		}
 	}
 
+	// Check for default value specification and if not specified then
+	// use the types zero value
+	defaultValue, err := f.defaultValue()
+	if err != nil {
+		return configError(f, "default", err)
+	}
+	options.trace(f, TraceDefaultParse, defaultValue)
+
+	// Warn when the default does not survive being parsed and rendered
+	// back to a string, e.g. a float value that cannot be represented
+	// exactly or a duration that is normalized
+	if options.Flags&WithDefaultRoundTripCheck != 0 && f.def != "" && !f.isSecret() {
+		checkRoundTrip(f, defaultValue, options)
+	}
+
+	// A flag defined by the caller is bound as is, so its default, help,
+	// and type apply rather than those of the field
+	if existing := flagSet.Lookup(f.long); existing != nil && options.Flags&WithReuseExistingFlags != 0 {
+		options.debugf("REUSE: '%s' = '--%s'", f.key, f.long)
+		if bind {
+			_ = v.BindPFlag(f.key, existing)
+		}
+		options.trace(f, TraceBind, defaultValue)
+		return nil
+	}
+
+	// Flags defined in the flag set other than by venom would cause pflag
+	// to panic when registered
+	if flagSet.Lookup(f.long) != nil {
+		return configErrorf(f, "long", "duplicate flag '--%s', already defined in the flag set", f.long)
+	}
+	if f.short != "" && flagSet.ShorthandLookup(f.short) != nil {
+		return configErrorf(f, "short", "duplicate flag '-%s', already defined in the flag set as '--%s'",
+			f.short, flagSet.ShorthandLookup(f.short).Name)
+	}
+
+	// Viper reports a key with a default as set, so a required or pointer
+	// field has a default only if one was specified
+	if bind && ((!f.isRequired() && !f.pointer) || f.def != "") {
+		options.debugf("SETDEF: '%s' = '%v'", f.key, f.redact(defaultValue))
+		v.SetDefault(f.key, defaultValue)
+	}
+	registerFlag(flagSet, f, defaultValue)
+
+	// The help for types such as durations, IP addresses, URLs, and
+	// times should display the default as it was specified rather than
+	// the normalized form produced by the flag value, e.g. 90s rather
+	// than 1m30s
+	flag := flagSet.Lookup(f.long)
+	if f.def != "" {
+		switch f.typ {
+		case durationType, ipType, ipMaskType, urlType, timeType:
+			flag.DefValue = f.def
+		}
+	}
+
+	// pflag renders float defaults in scientific notation when they are
+	// large, e.g. 1e+06, so they are displayed in decimal notation instead
+	if kind := f.typ.Kind(); (kind == reflect.Float32 || kind == reflect.Float64) && !isTextType(f.typ) && !f.isRaw() {
+		flag.DefValue = strconv.FormatFloat(reflect.ValueOf(defaultValue).Float(), 'f', -1, f.typ.Bits())
+	}
+
+	// The default of a secret is masked in the usage, but the flag's value
+	// is not, so that it functions as usual
+	if f.isSecret() && f.def != "" {
+		flag.DefValue = redacted
+	}
+
+	// Whitespace around the elements of a list is removed, as it is when
+	// the list is parsed from any other source
+	if f.trimsList() {
+		flag.Value = &trimmedListValue{Value: flag.Value}
+	}
+
+	// Override the placeholder displayed for the value in the usage. As
+	// the flag no longer reports its original type, viper will provide
+	// the flag's value as a string.
+	if name := f.tag.Get("typeName"); name != "" {
+		flag.Value = &typeNameValue{Value: flag.Value, name: name}
+	}
+
+	if group := f.tag.Get("group"); group != "" {
+		_ = flagSet.SetAnnotation(f.long, groupAnnotation, []string{group})
+	}
+
+	if hint := f.unitsHint(); hint != "" && options.Flags&WithUnitHints != 0 {
+		flag.Usage = strings.TrimSpace(flag.Usage + " " + hint)
+	}
+
+	// The environment variable of a secret is not advertised, nor is one
+	// that is not bound
+	if options.Flags&WithEnvInUsage != 0 && bind && f.env != "" && !f.isSecret() {
+		flag.Usage = strings.TrimSpace(flag.Usage + " [env: " + f.env + "]")
+	}
+
+	if msg := deprecationMessage(f.tag); msg != "" {
+		if err := flagSet.MarkDeprecated(f.long, msg); err != nil {
+			return configError(f, "deprecated", err)
+		}
+	}
+	if isTrue(f.tag.Get("hidden")) {
+		_ = flagSet.MarkHidden(f.long)
+	}
+
+	if err := registerAliases(flagSet, f, defaultValue); err != nil {
+		return err
+	}
+
+	if bind {
+		bindFlag(v, flagSet, f, flag)
+	}
+	options.trace(f, TraceBind, defaultValue)
 	return nil
 }
 
+// bindFlag binds the field's key to its flag or, if the field has aliases,
+// to the first of the flag and its aliases that was set
+func bindFlag(v *viper.Viper, flagSet *pflag.FlagSet, f *field, flag *pflag.Flag) {
+	if len(f.aliasLongs) > 0 {
+		_ = v.BindFlagValue(f.key, &aliasedFlag{flagSet: flagSet, field: f, flag: flag})
+		return
+	}
+	_ = v.BindPFlag(f.key, flag)
+}
+
+// deprecationMessage composes the deprecation message from the
+// `deprecated`, `deprecatedSince`, and `removeIn` tags, e.g. "deprecated
+// since v1.2, removed in v2.0; use --new". An empty string is returned if
+// the field is not deprecated.
+func deprecationMessage(tag reflect.StructTag) string {
+	var context []string
+	if since := tag.Get("deprecatedSince"); since != "" {
+		context = append(context, "deprecated since "+since)
+	}
+	if removeIn := tag.Get("removeIn"); removeIn != "" {
+		context = append(context, "removed in "+removeIn)
+	}
+
+	msg := tag.Get("deprecated")
+	if len(context) == 0 {
+		return msg
+	}
+	if msg == "" {
+		return strings.Join(context, ", ")
+	}
+	return strings.Join(context, ", ") + "; " + msg
+}
+
+// registerFlag adds a flag for the field to the flag set using the given
+// default value, which must be of the type returned by field.parse
+func registerFlag(flagSet *pflag.FlagSet, f *field, defaultValue interface{}) {
+	if f.isRaw() {
+		flagSet.StringP(f.long, f.short, defaultValue.(string), f.help)
+		return
+	}
+
+	// A count flag starts from the default and is incremented each time
+	// it is specified
+	if f.isCount() {
+		flagSet.CountP(f.long, f.short, f.help)
+		if f.def != "" {
+			flag := flagSet.Lookup(f.long)
+			_ = flag.Value.Set(strconv.Itoa(defaultValue.(int)))
+			flag.DefValue = flag.Value.String()
+		}
+		return
+	}
+
+	// An array flag appends the value of each occurrence, which may be
+	// omitted, as for a bool flag
+	if f.isArray() {
+		flagSet.VarP(newBoolArrayValue(defaultValue.([]bool)), f.long, f.short, f.help)
+		flagSet.Lookup(f.long).NoOptDefVal = "true"
+		return
+	}
+
+	switch f.typ {
+	case durationType:
+		if f.extendedDurations || f.tag.Get("unit") != "" {
+			flagSet.VarP(newDurationValue(defaultValue.(time.Duration), f.parseDuration), f.long, f.short, f.help)
+			return
+		}
+		flagSet.DurationP(f.long, f.short, defaultValue.(time.Duration), f.help)
+		return
+	case ipType:
+		flagSet.IPP(f.long, f.short, defaultValue.(net.IP), f.help)
+		return
+	case ipMaskType:
+		flagSet.IPMaskP(f.long, f.short, defaultValue.(net.IPMask), f.help)
+		return
+	case urlType:
+		flagSet.VarP(newURLValue(defaultValue.(url.URL)), f.long, f.short, f.help)
+		return
+	case timeType:
+		flagSet.VarP(newTimeValue(defaultValue.(time.Time), f.layouts()), f.long, f.short, f.help)
+		return
+	}
+
+	if e, ok := lookupEnum(f.typ); ok {
+		val := newEnumValue(e, reflect.ValueOf(defaultValue).Convert(reflect.TypeOf(int64(0))).Int())
+		flagSet.VarP(val, f.long, f.short, f.help)
+		return
+	}
+
+	if isTextType(f.typ) {
+		flagSet.VarP(newTextValue(defaultValue), f.long, f.short, f.help)
+		return
+	}
+
+	switch f.typ.Kind() {
+	case reflect.String:
+		flagSet.StringP(f.long, f.short, defaultValue.(string), f.help)
+	case reflect.Bool:
+		flagSet.BoolP(f.long, f.short, defaultValue.(bool), f.help)
+	case reflect.Int:
+		flagSet.IntP(f.long, f.short, defaultValue.(int), f.help)
+	case reflect.Int8:
+		flagSet.Int8P(f.long, f.short, defaultValue.(int8), f.help)
+	case reflect.Int16:
+		flagSet.Int16P(f.long, f.short, defaultValue.(int16), f.help)
+	case reflect.Int32:
+		flagSet.Int32P(f.long, f.short, defaultValue.(int32), f.help)
+	case reflect.Int64:
+		flagSet.Int64P(f.long, f.short, defaultValue.(int64), f.help)
+	case reflect.Uint:
+		flagSet.UintP(f.long, f.short, defaultValue.(uint), f.help)
+	case reflect.Uint8:
+		flagSet.Uint8P(f.long, f.short, defaultValue.(uint8), f.help)
+	case reflect.Uint16:
+		flagSet.Uint16P(f.long, f.short, defaultValue.(uint16), f.help)
+	case reflect.Uint32:
+		flagSet.Uint32P(f.long, f.short, defaultValue.(uint32), f.help)
+	case reflect.Uint64:
+		flagSet.Uint64P(f.long, f.short, defaultValue.(uint64), f.help)
+	case reflect.Float32:
+		flagSet.Float32P(f.long, f.short, defaultValue.(float32), f.help)
+	case reflect.Float64:
+		flagSet.Float64P(f.long, f.short, defaultValue.(float64), f.help)
+	case reflect.Complex64:
+		flagSet.VarP(newComplexValue(complex128(defaultValue.(complex64)), 64), f.long, f.short, f.help)
+	case reflect.Complex128:
+		flagSet.VarP(newComplexValue(defaultValue.(complex128), 128), f.long, f.short, f.help)
+	case reflect.Map:
+		switch f.typ.Elem().Kind() {
+		case reflect.String:
+			flagSet.StringToStringP(f.long, f.short, defaultValue.(map[string]string), f.help)
+		case reflect.Int:
+			flagSet.StringToIntP(f.long, f.short, defaultValue.(map[string]int), f.help)
+		case reflect.Slice:
+			flagSet.VarP(newStringSliceMapValue(f, defaultValue.(map[string][]string)), f.long, f.short, f.help)
+		}
+	case reflect.Slice:
+		if isBytesType(f.typ) {
+			if encoding, _ := f.encoding(); encoding == "base64" {
+				flagSet.BytesBase64P(f.long, f.short, defaultValue.([]byte), f.help)
+				return
+			}
+			flagSet.BytesHexP(f.long, f.short, defaultValue.([]byte), f.help)
+			return
+		}
+		if f.typ.Elem() == durationType {
+			if f.extendedDurations || f.tag.Get("unit") != "" {
+				flagSet.VarP(newDurationSliceValue(defaultValue.([]time.Duration), f.elem().parseDuration), f.long, f.short, f.help)
+				return
+			}
+			flagSet.DurationSliceP(f.long, f.short, defaultValue.([]time.Duration), f.help)
+			return
+		}
+
+		switch f.typ.Elem().Kind() {
+		case reflect.String:
+			flagSet.StringSliceP(f.long, f.short, defaultValue.([]string), f.help)
+		case reflect.Bool:
+			flagSet.BoolSliceP(f.long, f.short, defaultValue.([]bool), f.help)
+		case reflect.Int:
+			flagSet.IntSliceP(f.long, f.short, defaultValue.([]int), f.help)
+		case reflect.Int32:
+			flagSet.Int32SliceP(f.long, f.short, defaultValue.([]int32), f.help)
+		case reflect.Int64:
+			flagSet.Int64SliceP(f.long, f.short, defaultValue.([]int64), f.help)
+		case reflect.Uint:
+			flagSet.UintSliceP(f.long, f.short, defaultValue.([]uint), f.help)
+		case reflect.Float32:
+			flagSet.Float32SliceP(f.long, f.short, defaultValue.([]float32), f.help)
+		case reflect.Float64:
+			flagSet.Float64SliceP(f.long, f.short, defaultValue.([]float64), f.help)
+		}
+	}
+}
+
 // NewConfiguration constructs and returns a new PflagSet based on the structure tags
 // associated with the specified configSpecification interface.
 func NewConfiguration(configSpecification interface{}, prefix string, options ProcessingOptions, args []string) (*pflag.FlagSet, error) {
-	flagSet := pflag.NewFlagSet(path.Base(args[0]), pflag.ContinueOnError)
+	return NewNamedConfiguration(path.Base(args[0]), configSpecification, prefix, options, args)
+}
+
+// NewNamedConfiguration constructs and returns a new PflagSet with the given
+// name, rather than one named after the program, based on the structure tags
+// associated with the specified configSpecification interface. The name is
+// used by pflag in error and usage messages.
+func NewNamedConfiguration(name string, configSpecification interface{}, prefix string, options ProcessingOptions, args []string) (*pflag.FlagSet, error) {
+	flagSet := pflag.NewFlagSet(name, pflag.ContinueOnError)
 	if err := AddConfiguration(flagSet, configSpecification, prefix, options, args); err != nil {
 		return nil, err
 	}
 	return flagSet, nil
 }
+
+// Defaults returns the default value of each member of the specified
+// configSpecification interface, keyed by the viper key to which it would
+// be bound. Members without a default tag have the zero value of their
+// type. Nothing is registered with viper or pflag, so this can be used to
+// snapshot the defaults, e.g. for comparison across releases.
+func Defaults(configSpecification interface{}, prefix string, options ProcessingOptions) (map[string]interface{}, error) {
+	fields, err := describeFields(configSpecification, prefix, options)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := map[string]interface{}{}
+	for _, f := range fields {
+		if !f.parseable() {
+			continue
+		}
+		value, err := f.defaultValue()
+		if err != nil {
+			return nil, configError(f, "default", err)
+		}
+		defaults[f.key] = value
+	}
+	return defaults, nil
+}
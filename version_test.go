@@ -0,0 +1,127 @@
+/* Copyright 2020 Ciena Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package venom
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestVersionFlag(t *testing.T) {
+	var c struct {
+		Port int `default:"80"`
+	}
+	var out bytes.Buffer
+	p, err := New(&c, WithDefault, WithProgramName("tool"), WithVersion("1.2.3"), WithOutput(&out), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--version", "--port=90"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(); err != ErrVersion {
+		t.Fatalf("expected ErrVersion, got '%v'", err)
+	}
+	if got := out.String(); got != "tool version 1.2.3\n" {
+		t.Errorf("expected the version to be written, got '%s'", got)
+	}
+	if c.Port != 0 {
+		t.Errorf("expected no values to be resolved, got Port %d", c.Port)
+	}
+}
+
+func TestVersionUsage(t *testing.T) {
+	var c struct {
+		Port int `default:"80" help:"port to listen on"`
+	}
+	var out bytes.Buffer
+	p, err := New(&c, WithDefault, WithProgramName("tool"), WithVersion("1.2.3"), WithOutput(&out), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Parse([]string{"--help"}); err != pflag.ErrHelp {
+		t.Fatalf("expected pflag.ErrHelp, got '%v'", err)
+	}
+	usage := out.String()
+	if !strings.HasPrefix(usage, "tool version 1.2.3\n\nUsage of tool:\n") {
+		t.Errorf("expected the usage to start with the version, got:\n%s", usage)
+	}
+	if !strings.Contains(usage, "--version") || !strings.Contains(usage, "port to listen on") {
+		t.Errorf("expected the usage to list the flags, got:\n%s", usage)
+	}
+}
+
+func TestWithoutVersion(t *testing.T) {
+	var c struct {
+		Port int
+	}
+	p, err := New(&c, WithDefault, WithProgramName("tool"), WithViper(viper.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.FlagSet().Lookup("version") != nil {
+		t.Error("expected no version flag without WithVersion")
+	}
+	if err := p.Apply(); err != nil {
+		t.Errorf("unexpected error '%s'", err)
+	}
+}
+
+func TestReservedFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		spec interface{}
+		opts []Option
+		want string
+	}{
+		{name: "help", spec: &struct {
+			Help bool
+		}{}, want: "field 'Help': flag '--help' is reserved for help"},
+		{name: "short help", spec: &struct {
+			Host string `short:"h"`
+		}{}, want: "field 'Host': flag '-h' is reserved for help"},
+		{name: "version", spec: &struct {
+			Version string
+		}{}, opts: []Option{WithVersion("1.0")}, want: "field 'Version': flag '--version' is reserved for the version"},
+		{name: "dump", spec: &struct {
+			Dump bool
+		}{}, opts: []Option{WithConfigDumpFlag("dump")}, want: "field 'Dump': flag '--dump' is reserved for the configuration dump"},
+		{name: "help without auto help", spec: &struct {
+			Host string `short:"h"`
+		}{}, opts: []Option{WithoutAutoHelp}},
+		{name: "version without version", spec: &struct {
+			Version string
+		}{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New(test.spec, append([]Option{WithDefault, WithViper(viper.New())}, test.opts...)...)
+			if test.want == "" {
+				if err != nil {
+					t.Errorf("unexpected error '%s'", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.want {
+				t.Errorf("expected the error '%s', got '%v'", test.want, err)
+			}
+		})
+	}
+}